@@ -0,0 +1,154 @@
+package errors
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Capture decide whether file and line is recorded when the error is created
+type Capture int8
+
+const (
+	// CaptureDefault follow the capture policy when it is passed to WithCapture
+	// when it is used in the policy, file and line is recorded only if stack output is enabled
+	CaptureDefault Capture = iota
+	// CaptureNever doesn't record file and line, even if stack output is enabled
+	CaptureNever
+	// CaptureAlways record file and line
+	CaptureAlways
+	// CaptureSampled record file and line for a fraction of errors, decided by SampleRate of the policy
+	CaptureSampled
+)
+
+// CapturePolicy decide whether file and line is recorded, by the codes of the error being created
+// the codes is looked up in Codes first, then the http status of the codes is used
+// the stack set by SetStackOutput is only recorded for errors which is captured
+// the zero value is the default policy, which capture errors only when stack output is enabled
+type CapturePolicy struct {
+	// ServerError is used for codes with 5xx http status
+	ServerError Capture
+	// ClientError is used for codes with 4xx http status
+	ClientError Capture
+	// Other is used for other http status and error without codes
+	Other Capture
+	// Codes override the capture of codes, keyed by the identifier of codes like "NotFound"
+	Codes map[string]Capture
+	// SampleRate is the fraction of errors recorded by CaptureSampled, from 0 to 1
+	SampleRate float64
+}
+
+var (
+	// AlwaysCapture record file and line of every error, it is used by SetRuntimeOutput(true)
+	AlwaysCapture = CapturePolicy{ServerError: CaptureAlways, ClientError: CaptureAlways, Other: CaptureAlways}
+	// NeverCapture doesn't record file and line, even if stack output is enabled
+	NeverCapture = CapturePolicy{ServerError: CaptureNever, ClientError: CaptureNever, Other: CaptureNever}
+)
+
+// capturePolicy hold *CapturePolicy which is never changed after it is stored
+// it is read on every New so atomic.Value is used instead of lock
+// nothing is stored until SetCapturePolicy is called, the zero policy is used instead
+// so errors created by package level variables don't depend on the order of initialization
+var capturePolicy atomic.Value
+
+// SetCapturePolicy change the capture policy, it is safe to be called while errors is created
+// the policy is copied, so changing p later doesn't change the active policy
+func SetCapturePolicy(p CapturePolicy) {
+	p.Codes = copyCodes(p.Codes)
+	capturePolicy.Store(&p)
+}
+
+// GetCapturePolicy return a copy of the current capture policy
+func GetCapturePolicy() CapturePolicy {
+	p := *loadCapturePolicy()
+	p.Codes = copyCodes(p.Codes)
+	return p
+}
+
+func copyCodes(codes map[string]Capture) map[string]Capture {
+	if codes == nil {
+		return nil
+	}
+	copied := make(map[string]Capture, len(codes))
+	for name, c := range codes {
+		copied[name] = c
+	}
+	return copied
+}
+
+func loadCapturePolicy() *CapturePolicy {
+	p, ok := capturePolicy.Load().(*CapturePolicy)
+	if !ok {
+		return &CapturePolicy{}
+	}
+	return p
+}
+
+// WithCapture override the capture policy for this error only
+func WithCapture(c Capture) Option {
+	return func(e *Errs) {
+		e.capture = c
+	}
+}
+
+// capture return the capture of codes, without resolving CaptureSampled
+func (p *CapturePolicy) capture(code Codes) Capture {
+	if code == nil {
+		return p.Other
+	}
+	if c, ok := p.Codes[codeName(code)]; ok {
+		return c
+	}
+	_, status := code.ErrorAndCode()
+	switch {
+	case status >= 500 && status < 600:
+		return p.ServerError
+	case status >= 400 && status < 500:
+		return p.ClientError
+	default:
+		return p.Other
+	}
+}
+
+// ShouldCapture report whether file and line of error with the codes is recorded
+// the result of CaptureSampled is random, and CaptureDefault depend on SetStackOutput
+func (p *CapturePolicy) ShouldCapture(code Codes) bool {
+	return p.resolve(p.capture(code))
+}
+
+func (p *CapturePolicy) resolve(c Capture) bool {
+	switch c {
+	case CaptureAlways:
+		return true
+	case CaptureSampled:
+		return p.SampleRate > 0 && rand.Float64() < p.SampleRate
+	case CaptureDefault:
+		return IsStackEnabled()
+	default:
+		return false
+	}
+}
+
+// shouldCapture decide whether file and line of e is recorded
+// capture set by WithCapture take precedence over the policy
+func (e *Errs) shouldCapture() bool {
+	p := loadCapturePolicy()
+	c := e.capture
+	if c == CaptureDefault {
+		c = p.capture(e.GetCode())
+	}
+	e.sampled = c == CaptureSampled
+	return p.resolve(c)
+}
+
+// enabled report whether the policy can record file and line of any error
+func (p *CapturePolicy) enabled() bool {
+	if p.ServerError > CaptureNever || p.ClientError > CaptureNever || p.Other > CaptureNever {
+		return true
+	}
+	for _, c := range p.Codes {
+		if c > CaptureNever {
+			return true
+		}
+	}
+	return false
+}
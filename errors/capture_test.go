@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// errPackageLevel is created before any init function is run
+var errPackageLevel = New(NotFound, "package level")
+
+func TestCapturePolicyPackageLevel(t *testing.T) {
+	if errPackageLevel.GetCode() != NotFound {
+		t.Errorf("Expect package level error to have code %v but got %v", NotFound, errPackageLevel.GetCode())
+	}
+}
+
+func TestCapturePolicy(t *testing.T) {
+	SetCapturePolicy(CapturePolicy{
+		ServerError: CaptureAlways,
+		ClientError: CaptureNever,
+		Other:       CaptureSampled,
+		Codes:       map[string]Capture{"Conflict": CaptureAlways},
+		SampleRate:  0,
+	})
+	defer SetCapturePolicy(CapturePolicy{})
+
+	cases := []struct {
+		err    *Errs
+		expect bool
+	}{
+		{New(DatabaseError), true},
+		{New(Op("order.Get"), New(Internal)), true},
+		{New(NotFound), false},
+		{New(Conflict), true},
+		// sampled with rate 0 is never captured
+		{New("no codes"), false},
+		{NewWith(WithCode(NotFound), WithCapture(CaptureAlways)), true},
+		{NewWith(WithCode(DatabaseError), WithCapture(CaptureNever)), false},
+		{NewWith(WithCode(DatabaseError), WithCapture(CaptureDefault)), true},
+	}
+	for _, c := range cases {
+		file, _ := c.err.GetFileAndLine()
+		if captured := strings.HasSuffix(file, "capture_test.go"); captured != c.expect {
+			t.Errorf("Expect capture of %v to be %v but got file %q", c.err, c.expect, file)
+		}
+	}
+}
+
+func TestSetCapturePolicyCopy(t *testing.T) {
+	p := CapturePolicy{ServerError: CaptureAlways, Codes: map[string]Capture{"NotFound": CaptureAlways}}
+	SetCapturePolicy(p)
+	defer SetCapturePolicy(CapturePolicy{})
+
+	p.ServerError = CaptureNever
+	p.Codes["NotFound"] = CaptureNever
+	GetCapturePolicy().Codes["NotFound"] = CaptureNever
+	active := GetCapturePolicy()
+	if active.ServerError != CaptureAlways || active.Codes["NotFound"] != CaptureAlways {
+		t.Errorf("Expect active policy to not be changed but got %+v", active)
+	}
+}
+
+func TestCaptureSampled(t *testing.T) {
+	p := &CapturePolicy{Other: CaptureSampled, SampleRate: 1}
+	if !p.ShouldCapture(nil) {
+		t.Error("Expect sample rate 1 to always capture")
+	}
+	p.SampleRate = 0.5
+	var captured int
+	for i := 0; i < 1000; i++ {
+		if p.ShouldCapture(nil) {
+			captured++
+		}
+	}
+	if captured == 0 || captured == 1000 {
+		t.Errorf("Expect errors to be sampled but %d of 1000 is captured", captured)
+	}
+}
+
+func TestSetRuntimeOutputConcurrent(t *testing.T) {
+	defer SetRuntimeOutput(false)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(b bool) {
+			defer wg.Done()
+			SetRuntimeOutput(b)
+			SetStackOutput(0)
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			_ = New(NotFound)
+			_ = IsRuntimeEnabled()
+		}()
+	}
+	wg.Wait()
+
+	SetRuntimeOutput(true)
+	if !IsRuntimeEnabled() {
+		t.Error("Expect runtime output to be enabled")
+	}
+	SetRuntimeOutput(false)
+	if IsRuntimeEnabled() {
+		t.Error("Expect runtime output to be disabled")
+	}
+}
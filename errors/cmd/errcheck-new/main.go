@@ -0,0 +1,15 @@
+// errcheck-new vet the arguments of errors.New and report dropped *Errs
+//
+//	errcheck-new ./...
+//
+// See errors/newcheck for the list of checks.
+package main
+
+import (
+	"github.com/albert-widi/go_common/errors/newcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(newcheck.Analyzer)
+}
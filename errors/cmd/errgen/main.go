@@ -0,0 +1,60 @@
+// errgen generate Codes implementation and markdown error catalog from a JSON spec
+//
+//	//go:generate go run github.com/albert-widi/go_common/errors/cmd/errgen -spec codes.json -o codes_gen.go -doc ERRORS.md
+//
+// See errors/errgen for the format of the spec.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/albert-widi/go_common/errors/errgen"
+)
+
+func main() {
+	var (
+		specFile = flag.String("spec", "codes.json", "JSON spec of codes")
+		output   = flag.String("o", "codes_gen.go", "output go file")
+		doc      = flag.String("doc", "", "output markdown catalog, not generated if empty")
+		pkg      = flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of generated code, default to the package of go generate")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("errgen: ")
+
+	f, err := os.Open(*specFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	spec, err := errgen.ParseSpec(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if spec.Package == "" {
+		spec.Package = *pkg
+	}
+
+	source := filepath.Base(*specFile)
+	src, err := errgen.GenerateGo(spec, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+	if *doc == "" {
+		return
+	}
+	md, err := errgen.GenerateMarkdown(spec, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*doc, md, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Common fields name of request metadata
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldTenantID  = "tenant_id"
+	FieldTraceID   = "trace_id"
+)
+
+// ContextExtractor return fields of request metadata from context
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = []ContextExtractor{fieldsFromContext}
+)
+
+// RegisterContextExtractor register extractors used by NewCtx and WithContext
+// fields stored by ContextWithFields is always extracted
+func RegisterContextExtractor(extractor ...ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, extractor...)
+}
+
+// RegisterContextKey register an extractor which put the value of context key to field
+// this is useful when request metadata is already stored in context by other package
+//
+//	errors.RegisterContextKey(middleware.RequestIDKey, errors.FieldRequestID)
+func RegisterContextKey(key interface{}, field string) {
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		value := ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+		return Fields{field: value}
+	})
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields return a copy of context which store fields of request metadata
+// fields from the parent context is kept, unless it have the same key
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := make(Fields)
+	for key, value := range fieldsFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func fieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+	return fields
+}
+
+// NewCtx create Errs like New, and merge request metadata from context into fields
+// fields passed explicitly is not overridden by fields from context
+func NewCtx(ctx context.Context, args ...interface{}) *Errs {
+	opts, isBad := argsToOptions(args)
+	opts = append(opts, WithContext(ctx))
+	return newErrs(1, isBad, opts)
+}
+
+// WithContext merge request metadata from context into fields of the error
+// fields already in the error is not overridden, so it should be placed after WithFields
+func WithContext(ctx context.Context) Option {
+	return func(e *Errs) {
+		if ctx == nil {
+			return
+		}
+		extractorsMu.RLock()
+		defer extractorsMu.RUnlock()
+
+		var fields Fields
+		for _, extractor := range extractors {
+			for key, value := range extractor(ctx) {
+				if _, ok := e.fields[key]; ok {
+					continue
+				}
+				// copy the fields before it is changed, as it can be shared with other error
+				if fields == nil {
+					fields = make(Fields, len(e.fields))
+					for k, v := range e.fields {
+						fields[k] = v
+					}
+				}
+				if _, ok := fields[key]; !ok {
+					fields[key] = value
+				}
+			}
+		}
+		if fields != nil {
+			e.fields = fields
+		}
+	}
+}
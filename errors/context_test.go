@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type traceKey struct{}
+
+func TestNewCtx(t *testing.T) {
+	RegisterContextKey(traceKey{}, FieldTraceID)
+
+	ctx := ContextWithFields(context.Background(), Fields{FieldRequestID: "req-1", FieldUserID: 10})
+	ctx = ContextWithFields(ctx, Fields{FieldTenantID: "tenant-1"})
+	ctx = context.WithValue(ctx, traceKey{}, "trace-1")
+
+	inner := New("inner error", Fields{"order_id": 1})
+	err := NewCtx(ctx, inner, Op("order.Get"))
+	expect := Fields{
+		"order_id":     1,
+		FieldRequestID: "req-1",
+		FieldUserID:    10,
+		FieldTenantID:  "tenant-1",
+		FieldTraceID:   "trace-1",
+	}
+	if !reflect.DeepEqual(err.GetFields(), expect) {
+		t.Errorf("Expect %v but got %v", expect, err.GetFields())
+	}
+	if !reflect.DeepEqual(inner.GetFields(), Fields{"order_id": 1}) {
+		t.Errorf("Expect fields of inner error to not be changed but got %v", inner.GetFields())
+	}
+
+	err = NewCtx(ctx, "explicit", Fields{FieldUserID: 20})
+	if err.GetFields()[FieldUserID] != 20 {
+		t.Errorf("Expect explicit fields to not be overridden but got %v", err.GetFields()[FieldUserID])
+	}
+
+	err = NewWith(WithString("no metadata"), WithContext(context.Background()))
+	if err.GetFields() != nil {
+		t.Errorf("Expect no fields but got %v", err.GetFields())
+	}
+}
@@ -0,0 +1,259 @@
+// Package errgen generate Codes implementation and error catalog from a JSON spec
+//
+// Defining a new Codes type means writing the same switch of ErrorAndCode, Retryable and String
+// like DefaultCodes. errgen generate them from a spec of codes:
+//
+//	{
+//	    "type": "OrderCodes",
+//	    "codes": [
+//	        {
+//	            "name": "OrderNotFound",
+//	            "message": "Order not found",
+//	            "http_status": 404,
+//	            "description": "The order doesn't exist or is not owned by the user"
+//	        }
+//	    ]
+//	}
+//
+// See errors/cmd/errgen for the go generate command.
+package errgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/albert-widi/go_common/errors"
+)
+
+// Spec is the spec of a Codes type
+type Spec struct {
+	// Package is the package name of generated code
+	Package string `json:"package,omitempty"`
+	// Type is the name of Codes type, for example OrderCodes
+	Type string `json:"type"`
+	// Description is the doc comment of Codes type
+	Description string `json:"description,omitempty"`
+	Codes       []Code `json:"codes"`
+}
+
+// Code is the spec of a code, the first code is the zero value of the type
+type Code struct {
+	// Name is the name of the constant and the identifier of the code, it must be unique across registered codes
+	Name string `json:"name"`
+	// Message is the error string returned by ErrorAndCode
+	Message string `json:"message"`
+	// HTTPStatus is the http status returned by ErrorAndCode, 500 if empty
+	HTTPStatus  int    `json:"http_status,omitempty"`
+	Retryable   bool   `json:"retryable,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ParseSpec read and validate JSON spec
+func ParseSpec(r io.Reader) (*Spec, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	spec := new(Spec)
+	if err := dec.Decode(spec); err != nil {
+		return nil, errors.New(errors.Op("errgen.ParseSpec"), errors.InvalidArgument, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Validate check the spec and fill the default http status
+func (s *Spec) Validate() error {
+	op := errors.Op("errgen.Validate")
+	if !token.IsIdentifier(s.Type) || !token.IsExported(s.Type) {
+		return errors.New(op, errors.InvalidArgument, fmt.Sprintf("type %q is not an exported identifier", s.Type))
+	}
+	if len(s.Codes) == 0 {
+		return errors.New(op, errors.InvalidArgument, "spec doesn't have any code")
+	}
+	names := make(map[string]bool, len(s.Codes))
+	for i := range s.Codes {
+		code := &s.Codes[i]
+		if !token.IsIdentifier(code.Name) || !token.IsExported(code.Name) {
+			return errors.New(op, errors.InvalidArgument, fmt.Sprintf("code %q is not an exported identifier", code.Name))
+		}
+		if names[code.Name] {
+			return errors.New(op, errors.AlreadyExists, fmt.Sprintf("code %s is defined more than once", code.Name))
+		}
+		names[code.Name] = true
+		// identifier must be unique in the registry, otherwise decoded errors get the wrong codes
+		if _, ok := errors.LookupCodes(code.Name); ok {
+			return errors.New(op, errors.AlreadyExists, fmt.Sprintf("code %s is already registered by errors package", code.Name))
+		}
+		if code.Message == "" {
+			return errors.New(op, errors.InvalidArgument, fmt.Sprintf("code %s doesn't have message", code.Name))
+		}
+		if code.HTTPStatus == 0 {
+			code.HTTPStatus = http.StatusInternalServerError
+		}
+		if code.HTTPStatus < 400 || code.HTTPStatus > 599 {
+			return errors.New(op, errors.InvalidArgument, fmt.Sprintf("code %s have invalid http status %d", code.Name, code.HTTPStatus))
+		}
+	}
+	return nil
+}
+
+var funcs = template.FuncMap{
+	"comment": func(s string) string {
+		return strings.Replace(strings.TrimSpace(s), "\n", "\n// ", -1)
+	},
+	// lower the first letter so it read as a sentence after the name, acronyms are kept
+	"lowerFirst": func(s string) string {
+		if len(s) > 1 && unicode.IsUpper(rune(s[0])) && !unicode.IsUpper(rune(s[1])) {
+			return string(unicode.ToLower(rune(s[0]))) + s[1:]
+		}
+		return s
+	},
+	"statusText": func(status int) string {
+		if text := http.StatusText(status); text != "" {
+			return text
+		}
+		if status == errors.StatusClientClosedRequest {
+			return "Client Closed Request"
+		}
+		return "Unknown"
+	},
+	"retryable": func(codes []Code) []string {
+		var names []string
+		for _, code := range codes {
+			if code.Retryable {
+				names = append(names, code.Name)
+			}
+		}
+		return names
+	},
+	"join": strings.Join,
+	"cell": func(s string) string {
+		return strings.Replace(strings.Replace(strings.TrimSpace(s), "|", `\|`, -1), "\n", " ", -1)
+	},
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(funcs).Parse(`// Code generated by errgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"strconv"
+
+	"github.com/albert-widi/go_common/errors"
+)
+{{with .Spec}}
+{{if .Description}}// {{comment .Description}}{{else}}// {{.Type}} implement errors.Codes{{end}}
+type {{.Type}} int
+
+const (
+{{- range $i, $code := .Codes}}
+	{{if $code.Description}}// {{$code.Name}} {{comment (lowerFirst $code.Description)}}
+	{{end}}{{$code.Name}}{{if eq $i 0}} {{$.Spec.Type}} = iota{{end}}
+{{- end}}
+)
+
+var _ errors.Codes = {{(index .Codes 0).Name}}
+
+func init() {
+	errors.RegisterCodes({{range $i, $code := .Codes}}{{if $i}}, {{end}}{{$code.Name}}{{end}})
+}
+
+// ErrorAndCode will return the error string and http status of the codes
+func (c {{.Type}}) ErrorAndCode() (string, int) {
+	switch c {
+{{- range .Codes}}
+	case {{.Name}}:
+		return {{printf "%q" .Message}}, {{.HTTPStatus}}
+{{- end}}
+	default:
+		return "Internal server error", 500
+	}
+}
+
+func (c {{.Type}}) Err() error {
+	err, _ := c.ErrorAndCode()
+	return errors.New(err)
+}
+
+// Retryable report whether the operation failed with the codes is worth to be retried
+func (c {{.Type}}) Retryable() bool {
+{{- with retryable .Codes}}
+	switch c {
+	case {{join . ", "}}:
+		return true
+	default:
+		return false
+	}
+{{- else}}
+	return false
+{{- end}}
+}
+
+// String return the identifier of the codes
+func (c {{.Type}}) String() string {
+	switch c {
+{{- range .Codes}}
+	case {{.Name}}:
+		return {{printf "%q" .Name}}
+{{- end}}
+	default:
+		return "{{.Type}}(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+{{- end}}
+`))
+
+// GenerateGo generate go source of the spec, source is the name of spec file written in the header
+func GenerateGo(spec *Spec, source string) ([]byte, error) {
+	if spec.Package == "" {
+		return nil, errors.New(errors.Op("errgen.GenerateGo"), errors.InvalidArgument, "package is empty")
+	}
+	var buf bytes.Buffer
+	err := goTemplate.Execute(&buf, map[string]interface{}{
+		"Source":  source,
+		"Package": spec.Package,
+		"Spec":    spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.New(errors.Op("errgen.GenerateGo"), err, errors.Fields{"source": buf.String()})
+	}
+	return src, nil
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(`<!-- Code generated by errgen from {{.Source}}. DO NOT EDIT. -->
+{{with .Spec}}
+# {{.Type}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+| Code | HTTP status | Message | Retryable | Description |
+|------|-------------|---------|-----------|-------------|
+{{- range .Codes}}
+| ` + "`{{.Name}}`" + ` | {{.HTTPStatus}} {{statusText .HTTPStatus}} | {{cell .Message}} | {{if .Retryable}}yes{{else}}no{{end}} | {{cell .Description}} |
+{{- end}}
+{{end -}}
+`))
+
+// GenerateMarkdown generate markdown catalog of the spec for API docs
+// the code is the value of "code" field in the http response written by errors.WriteHTTP
+func GenerateMarkdown(spec *Spec, source string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := markdownTemplate.Execute(&buf, map[string]interface{}{
+		"Source": source,
+		"Spec":   spec,
+	})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,83 @@
+package errgen
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albert-widi/go_common/errors"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	f, err := os.Open("testdata/codes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	spec, err := ParseSpec(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Codes[2].HTTPStatus != 500 {
+		t.Errorf("Expect %d but got %d", 500, spec.Codes[2].HTTPStatus)
+	}
+
+	src, err := GenerateGo(spec, "codes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := GenerateMarkdown(spec, "codes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "testdata/codes_gen.go.golden", src)
+	checkGolden(t, "testdata/codes.md.golden", md)
+}
+
+func checkGolden(t *testing.T, file string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := ioutil.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expect) {
+		t.Errorf("Expect %s to be up to date, run go test -update, but got:\n%s", filepath.Base(file), got)
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	cases := []struct {
+		spec   string
+		expect string
+	}{
+		{`{"type": "orderCodes", "codes": [{"name": "A", "message": "a"}]}`, "not an exported identifier"},
+		{`{"type": "OrderCodes"}`, "doesn't have any code"},
+		{`{"type": "OrderCodes", "codes": [{"name": "A", "message": "a"}, {"name": "A", "message": "a"}]}`, "more than once"},
+		{`{"type": "OrderCodes", "codes": [{"name": "NotFound", "message": "a"}]}`, "already registered"},
+		{`{"type": "OrderCodes", "codes": [{"name": "A"}]}`, "doesn't have message"},
+		{`{"type": "OrderCodes", "codes": [{"name": "A", "message": "a", "http_status": 200}]}`, "invalid http status"},
+		{`{"type": "OrderCodes", "codes": [{"name": "A", "message": "a", "status": 404}]}`, "unknown field"},
+	}
+	for _, c := range cases {
+		_, err := ParseSpec(strings.NewReader(c.spec))
+		if err == nil || !strings.Contains(err.Error(), c.expect) {
+			t.Errorf("Expect error of %s to contain %q but got %v", c.spec, c.expect, err)
+			continue
+		}
+		var errs *errors.Errs
+		if !errors.As(err, &errs) || errs.GetCode() == nil {
+			t.Errorf("Expect error with codes but got %v", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package errmetrics count errors.Errs with prometheus by codes, http status and op
+//
+// the counter is registered as errors hook, so it is opt-in and the errors package
+// doesn't depend on prometheus.
+package errmetrics
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/albert-widi/go_common/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter count errors by event, codes, http status and op
+type Counter struct {
+	vec    *prometheus.CounterVec
+	events map[errors.HookEvent]bool
+}
+
+// New create counter named <namespace>_errors_total for the given events
+// errors is counted when it is rendered or logged if no event is given,
+// counting on errors.HookCreate will count every wrapping of the error
+func New(namespace string, events ...errors.HookEvent) *Counter {
+	if len(events) == 0 {
+		events = []errors.HookEvent{errors.HookRender, errors.HookLog}
+	}
+	c := &Counter{
+		vec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "errors_total",
+			Help:      "Number of errors by event, codes, http status and op",
+		}, []string{"event", "code", "httpcode", "op"}),
+		events: make(map[errors.HookEvent]bool, len(events)),
+	}
+	for _, e := range events {
+		c.events[e] = true
+	}
+	return c
+}
+
+// Register create the counter, register it to the default prometheus registry and to errors hook
+// it should be called once, when the service is started
+func Register(namespace string, events ...errors.HookEvent) (*Counter, error) {
+	c := New(namespace, events...)
+	if err := prometheus.Register(c.Collector()); err != nil {
+		return nil, err
+	}
+	errors.RegisterHook(c.Hook)
+	return c, nil
+}
+
+// Collector return the prometheus collector of the counter
+func (c *Counter) Collector() prometheus.Collector {
+	return c.vec
+}
+
+// Hook count the error, it is passed to errors.RegisterHook
+func (c *Counter) Hook(event errors.HookEvent, err error) {
+	if err == nil || !c.events[event] {
+		return
+	}
+	c.vec.With(Labels(event, err)).Inc()
+}
+
+// Labels return prometheus labels of the error
+// code is empty for error without codes and op is the outermost op in the error chain
+// unregistered RemoteCodes is labelled as "remote", its http status is still in the httpcode label
+func Labels(event errors.HookEvent, err error) prometheus.Labels {
+	labels := prometheus.Labels{
+		"event":    event.String(),
+		"code":     "",
+		"httpcode": strconv.Itoa(errors.HTTPStatus(err)),
+		"op":       "",
+	}
+	var errs *errors.Errs
+	if !errors.As(err, &errs) {
+		return labels
+	}
+	if code := errs.GetCode(); code != nil {
+		labels["code"] = codeName(code)
+	}
+	if ops := errs.GetOps(); len(ops) > 0 {
+		labels["op"] = string(ops[0])
+	}
+	return labels
+}
+
+// remoteCode is the code label of codes decoded from other services which is not registered
+// the name of such codes come from the response body, so it is not used to keep the label cardinality bounded
+const remoteCode = "remote"
+
+// codeName use the same identifier of codes as the http response
+func codeName(code errors.Codes) string {
+	if remote, ok := code.(errors.RemoteCodes); ok {
+		if _, registered := errors.LookupCodes(remote.Name); !registered {
+			return remoteCode
+		}
+	}
+	if s, ok := code.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(code)
+}
@@ -0,0 +1,69 @@
+package errmetrics
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/albert-widi/go_common/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLabels(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect prometheus.Labels
+	}{
+		{
+			err:    fmt.Errorf("plain"),
+			expect: prometheus.Labels{"event": "render", "code": "", "httpcode": "500", "op": ""},
+		},
+		{
+			err:    errors.New(errors.NotFound),
+			expect: prometheus.Labels{"event": "render", "code": "NotFound", "httpcode": "404", "op": ""},
+		},
+		{
+			err:    errors.New(errors.Op("order.Get"), errors.New(errors.Op("repo.Get"), errors.DatabaseError)),
+			expect: prometheus.Labels{"event": "render", "code": "DatabaseError", "httpcode": "500", "op": "order.Get"},
+		},
+		{
+			err:    errors.New(errors.RemoteCodes{Name: "OrderLocked-12345", HTTPStatus: 409}),
+			expect: prometheus.Labels{"event": "render", "code": "remote", "httpcode": "409", "op": ""},
+		},
+	}
+	for _, c := range cases {
+		if labels := Labels(errors.HookRender, c.err); !reflect.DeepEqual(labels, c.expect) {
+			t.Errorf("Expect %v for %v but got %v", c.expect, c.err, labels)
+		}
+	}
+}
+
+func TestCounterHook(t *testing.T) {
+	c := New("test")
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(c.Collector()); err != nil {
+		t.Fatal(err)
+	}
+	c.Hook(errors.HookRender, errors.New(errors.NotFound))
+	c.Hook(errors.HookLog, errors.New(errors.NotFound))
+	// HookCreate is not counted by default
+	c.Hook(errors.HookCreate, errors.New(errors.NotFound))
+	c.Hook(errors.HookRender, nil)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, f := range families {
+		if f.GetName() != "test_errors_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			total += m.GetCounter().GetValue()
+		}
+	}
+	if total != 2 {
+		t.Errorf("Expect %v but got %v", 2, total)
+	}
+}
@@ -3,35 +3,56 @@ package errors
 // errors package inspired and a subset copy of upspin project
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
+	"reflect"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 
 	"log"
 )
 
-var runtimeOutput bool
-
 // SetRuntimeOutput will provide error information where the error is happened
+// it set the capture policy to AlwaysCapture or the default policy, use SetCapturePolicy for finer control
 func SetRuntimeOutput(b bool) {
-	runtimeOutput = b
+	if b {
+		SetCapturePolicy(AlwaysCapture)
+		return
+	}
+	SetCapturePolicy(CapturePolicy{})
 }
 
-// IsRuntimeEnabled to check the status of runtimeOutput
+// IsRuntimeEnabled to check whether the capture policy can record file and line of any error
 func IsRuntimeEnabled() bool {
-	return runtimeOutput
+	return loadCapturePolicy().enabled()
 }
 
+// Fields is additional context of the error
+// fields of *Errs is never changed after the error is created, every change create a new map,
+// so the fields can be shared by copies of the error and read concurrently
 type Fields map[string]interface{}
 
+// Op describe an operation, usually the package and function name such as "order.Create"
+// Each layer of Errs can record its own Op, so the logical path of the error can be printed without a stack trace
+type Op string
+
 // Errs struct
 type Errs struct {
 	err error
+	// detail is the string or error passed together with a previous *Errs
+	// it describe this layer of the error, while the previous *Errs is kept as the cause
+	detail error
 	// Codes used for Errs to identify known errors in the application
 	// If the error is expected by Errs object, the errors will be shown as listed in Codes
 	code    Codes
 	message string
 
+	// Op is the operation being performed when the error is created
+	op Op
+
 	// Traces used to add function traces to errors, this is different from context
 	// While context is used to add more information about the error, traces is used
 	// for easier function tracing purposes without hurting heap too much
@@ -45,138 +66,441 @@ type Errs struct {
 	// this is used to simplify error message stack
 	messages []string
 
+	// severity is set explicitly, otherwise it is inferred from the codes
+	severity Severity
+
+	// capture is set by WithCapture to override the capture policy
+	capture Capture
+
+	// callerSkip is the number of extra frames skipped when the caller is recorded, set by WithCallerSkip
+	callerSkip int
+
 	// var for runtime output
 	file string
 	line int
+
+	// stack is the program counters recorded when the error is created
+	// frames is resolved lazily, so recording the stack is cheap
+	stack []uintptr
+
+	// sampled is set when capturing the error is decided by sampling
+	// the call site is not used by Fingerprint, so the fingerprint doesn't depend on the sample
+	sampled bool
 }
 
 var _ error = (*Errs)(nil)
 
 // New Errs
+// New is a shim of NewWith, every argument is converted to Option based on its type
+// unsupported argument is logged and ignored, use NewWith to let the compiler check the arguments
 func New(args ...interface{}) *Errs {
+	opts, isBad := argsToOptions(args)
+	return newErrs(1, isBad, opts)
+}
+
+// argsToOptions convert arguments of New to options
+// unsupported argument is logged with the caller of New
+func argsToOptions(args []interface{}) ([]Option, bool) {
 	var (
-		er    error
+		opts  = make([]Option, 0, len(args))
 		isBad bool
 	)
-	err := &Errs{err: errors.New("Unknown error")}
 	for _, arg := range args {
 		switch arg.(type) {
 		case string:
-			er = errors.New(arg.(string))
+			opts = append(opts, WithString(arg.(string)))
+		// the previous Errs become the cause of the new one
 		case *Errs:
-			// copy and put the errors back
-			errcpy := *arg.(*Errs)
-			err = &errcpy
+			opts = append(opts, Wrap(arg.(*Errs)))
 		// error should be placed below *Errs
 		// implementation of Error() string will detect *Errs as error
 		case error:
-			er = arg.(error)
+			opts = append(opts, Wrap(arg.(error)))
 		case Codes:
-			err.code = arg.(Codes)
-			errString, _ := err.code.ErrorAndCode()
-			er = errors.New(errString)
-		// Fields cannot be appended
-		// new fields will always replace the old fields
+			opts = append(opts, WithCode(arg.(Codes)))
+		case Op:
+			opts = append(opts, WithOp(arg.(Op)))
+		case Severity:
+			opts = append(opts, WithSeverity(arg.(Severity)))
+		// Fields is merged into the fields carried from the previous Errs
+		// the later Fields override the earlier one on the same key
 		case Fields:
-			err.fields = arg.(Fields)
+			opts = append(opts, MergeFields(arg.(Fields), MergeOverride))
 		// []string is detected as Errs.Messages
-		// Messages can be appended, but might need to create a different type in the future
 		case []string:
-			if err.messages == nil {
-				err.messages = make([]string, 0)
-			}
-			msgs := arg.([]string)
-			err.messages = append(err.messages, msgs...)
+			opts = append(opts, WithMessages(arg.([]string)...))
 		default:
 			// the default error is unknown
-			_, file, line, _ := runtime.Caller(1)
+			_, file, line, _ := runtime.Caller(2)
 			log.Printf("errors.Errs: bad call from %s:%d: %v", file, line, args)
+			isBad = true
 		}
 	}
-	// if er have value then set errrors.error to er
-	if er != nil {
-		err.err = er
+	return opts, isBad
+}
+
+// newErrs create Errs from options and record the runtime information
+// skip 0 is the function calling newErrs
+func newErrs(skip int, isBad bool, opts []Option) *Errs {
+	err := &Errs{}
+	for _, opt := range opts {
+		opt(err)
 	}
-	// only get the runtime file and line if err is defined
-	if runtimeOutput && !isBad {
-		_, err.file, err.line, _ = runtime.Caller(1)
+	defer RunHooks(HookCreate, err)
+	if isBad {
+		return err
 	}
+	skip += err.callerSkip
+	// the capture policy decide first, so the stack output cannot record errors the policy exclude
+	if !err.shouldCapture() {
+		return err
+	}
+	// only record the program counters, file and line is resolved when needed
+	depth := getStackDepth()
+	if depth == 0 {
+		depth = 1
+	}
+	err.stack = callers(skip+1, depth)
 	return err
 }
 
 // WithCodes give a safer passing of codes to errors as compiler/linter will check the interface{} implementation
 func WithCodes(codes Codes) *Errs {
-	return New(codes)
+	return newErrs(1, false, []Option{WithCode(codes)})
 }
 
+// Error print the op chain, codes and the underlying error
+// for example: "order.Create: repo.Insert: Database error: duplicate key"
 func (e *Errs) Error() string {
-	return e.err.Error()
+	b := new(bytes.Buffer)
+	if e.op != "" {
+		b.WriteString(string(e.op))
+	}
+	prev, isErrs := e.err.(*Errs)
+	// code is not printed if the previous error have the same code, to avoid duplication
+	if e.code != nil && !(isErrs && sameCode(e.code, prev.code)) {
+		errString, _ := e.code.ErrorAndCode()
+		pad(b, ": ")
+		b.WriteString(errString)
+	}
+	if e.detail != nil {
+		pad(b, ": ")
+		b.WriteString(e.detail.Error())
+	}
+	if e.err != nil {
+		pad(b, ": ")
+		b.WriteString(e.err.Error())
+	}
+	if b.Len() == 0 {
+		return "Unknown error"
+	}
+	return b.String()
+}
+
+// pad appends str to the buffer if the buffer already has some data
+func pad(b *bytes.Buffer, str string) {
+	if b.Len() == 0 {
+		return
+	}
+	b.WriteString(str)
+}
+
+// Unwrap return the underlying error of Errs
+// the underlying error can be another *Errs, so it is possible to walk the chain using standard errors package
+func (e *Errs) Unwrap() error {
+	return e.err
+}
+
+// Is report whether target is matched with Errs
+// target is matched if it is in the detail of the error, or it is an *Errs which only have the same code
+// *Errs target with error, op or fields is matched by identity, use Match to match by template
+// other kind of target is checked by the standard errors package through Unwrap
+func (e *Errs) Is(target error) bool {
+	if e.detail != nil && errors.Is(e.detail, target) {
+		return true
+	}
+	t, ok := target.(*Errs)
+	if !ok || !t.isCodeOnly() {
+		return false
+	}
+	return sameCode(e.code, t.code)
+}
+
+// isCodeOnly report whether the error carry nothing but a code, like New(NotFound)
+func (e *Errs) isCodeOnly() bool {
+	return e.code != nil && e.err == nil && e.detail == nil && e.op == "" && len(e.fields) == 0
+}
+
+// As set target to the detail of the error or to the code of Errs if target is a pointer to the code type or to Codes
+// *Errs target is handled by the standard errors package
+func (e *Errs) As(target interface{}) bool {
+	if e.detail != nil && errors.As(e.detail, target) {
+		return true
+	}
+	if e.code == nil || target == nil {
+		return false
+	}
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return false
+	}
+	// other interfaces implemented by the code, like fmt.Stringer, are looked up further in the chain
+	if elem := val.Elem().Type(); elem != codesType && elem != reflect.TypeOf(e.code) {
+		return false
+	}
+	val.Elem().Set(reflect.ValueOf(e.code))
+	return true
+}
+
+var codesType = reflect.TypeOf((*Codes)(nil)).Elem()
+
+// Is is a shortcut of standard errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As is a shortcut of standard errors.As
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap is a shortcut of standard errors.Unwrap
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// sameCode compare two codes without panicking on uncomparable codes
+func sameCode(c1, c2 Codes) bool {
+	if c1 == nil || c2 == nil {
+		return c1 == c2
+	}
+	if reflect.TypeOf(c1) != reflect.TypeOf(c2) {
+		return false
+	}
+	if !reflect.TypeOf(c1).Comparable() {
+		return reflect.DeepEqual(c1, c2)
+	}
+	return c1 == c2
 }
 
 // SetMessage for error
+// the error is changed in place, use WithUserMessage to set the message on a shared error
 func (e *Errs) SetMessage(message string) {
 	e.message = message
 }
 
+// WithUserMessage return a copy of the error with the user message set
+// the error itself is not changed, so the message can be set on a shared error
+func (e *Errs) WithUserMessage(message string) *Errs {
+	copied := e.copy()
+	copied.message = message
+	return copied
+}
+
 // GetMessage return message for error
+// the outermost message in the error chain is returned, so the message is kept after the error is wrapped
 func (e *Errs) GetMessage() string {
-	return e.message
+	return e.userMessage()
 }
 
-// GetTrace return traces
+// GetCode return codes of the error
+// the outermost codes in the error chain is returned
+func (e *Errs) GetCode() Codes {
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		if errs, ok := err.(*Errs); ok && errs.code != nil {
+			return errs.code
+		}
+	}
+	return nil
+}
+
+// GetOp return op of the error
+func (e *Errs) GetOp() Op {
+	return e.op
+}
+
+// GetOps return all ops in the error chain, started from the outermost error
+func (e *Errs) GetOps() []Op {
+	var ops []Op
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		errs, ok := err.(*Errs)
+		if !ok || errs.op == "" {
+			continue
+		}
+		ops = append(ops, errs.op)
+	}
+	return ops
+}
+
+// GetTrace return traces of the error chain
+// traces of the innermost error is returned first, as it is the first function in the trace
 func (e *Errs) GetTrace() []string {
-	return e.traces
+	var chain []*Errs
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		if errs, ok := err.(*Errs); ok {
+			chain = append(chain, errs)
+		}
+	}
+	var traces []string
+	for i := len(chain) - 1; i >= 0; i-- {
+		traces = append(traces, chain[i].traces...)
+	}
+	return traces
+}
+
+// Trace return a copy of the error with function traces added, the error itself is not changed
+// so a shared error like a sentinel can be traced concurrently
+// if fn is empty, the name of the function calling Trace is used
+// error that is not *Errs will be wrapped into *Errs
+func Trace(err error, fn ...string) error {
+	if err == nil {
+		return nil
+	}
+	if len(fn) == 0 {
+		fn = []string{callerName(2)}
+	}
+	errs, ok := err.(*Errs)
+	if !ok {
+		return &Errs{err: err, traces: fn}
+	}
+	traced := errs.copy()
+	traced.traces = append(traced.traces, fn...)
+	return traced
 }
 
-// GetFields return available fields in errors
+// callerName return the function name of the caller, without the package path
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// GetFields return a copy of available fields in errors, changing it doesn't change the error
 func (e *Errs) GetFields() Fields {
-	return e.fields
+	return e.fields.Copy()
 }
 
 // GetMessages return array of errors, this is depends by what kind of messages can be exists in the stack.
+// a copy is returned, so appending to it doesn't change the error
 func (e *Errs) GetMessages() []string {
-	return e.messages
+	return appendMessages(e.messages, nil)
 }
 
-// GetFileAndLine is part of runtimeOutput, as runtime will give file and line information
-// will give empty string and 0 if runtimeOutput is false
+// GetFileAndLine is part of runtime output, as runtime will give file and line information
+// will give empty string and 0 if the error is not captured and stack output is disabled
 func (e *Errs) GetFileAndLine() (string, int) {
-	return e.file, e.line
+	if e.line != 0 || len(e.stack) == 0 {
+		return e.file, e.line
+	}
+	frame, _ := runtime.CallersFrames(e.stack[:1]).Next()
+	return frame.File, frame.Line
 }
 
 /*
-Match will match two strings error through a fuzzy matching
-Need some improvement in fuzzy matching, not all cases is covered
+Match will match an error with a template, inspired by upspin errors.Match
+
+If both errors are *Errs, the first error is the template. Only non-zero parts of the template are checked:
+  - codes is matched with the codes of the error returned by GetCode
+  - op is matched if the op exists in the op chain of the error
+  - fields is matched if the fields is a subset of the fields of the error
+  - underlying error is matched recursively with the error chain, *Errs as template and other errors by its string
+
+If one of the error is not *Errs, it is looked up in the error chain of the other error, so the
+original error is still matched after it is wrapped several times by Errs
 */
 
 // Match error
-func Match(errs1, errs2 error) bool {
-	if errs1 == nil && errs2 == nil {
-		return true
+func Match(template, err error) bool {
+	if template == nil || err == nil {
+		return template == nil && err == nil
 	}
 
-	if errs1 != nil {
-		err1, ok := errs1.(*Errs)
-		if ok {
-			errs1 = err1.err
+	t, ok := template.(*Errs)
+	if !ok {
+		return matchCause(template, err)
+	}
+	var e *Errs
+	if !errors.As(err, &e) {
+		return matchCause(err, template)
+	}
+	return matchTemplate(t, e)
+}
+
+func matchTemplate(t, e *Errs) bool {
+	if t.code != nil && !sameCode(t.code, e.GetCode()) {
+		return false
+	}
+	if t.op != "" && !containsOp(e.GetOps(), t.op) {
+		return false
+	}
+	if len(t.fields) > 0 {
+		fields := e.fields
+		for key, value := range t.fields {
+			if v, ok := fields[key]; !ok || !reflect.DeepEqual(v, value) {
+				return false
+			}
 		}
-	} else {
-		errs1 = errors.New("nil")
 	}
 
-	if errs2 != nil {
-		err2, ok := errs2.(*Errs)
-		if ok {
-			errs2 = err2.err
+	prev, ok := t.err.(*Errs)
+	if !ok {
+		return t.err == nil || matchCause(t.err, e)
+	}
+	for err := error(e); err != nil; err = errors.Unwrap(err) {
+		if errs, ok := err.(*Errs); ok && matchTemplate(prev, errs) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOp(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
 		}
-	} else {
-		errs2 = errors.New("nil")
 	}
+	return false
+}
 
-	if errs1.Error() != errs2.Error() {
+// matchCause report whether target is exists in the error chain of err
+// target is matched if it is the same error or it have the same string
+func matchCause(target, err error) bool {
+	target = matchLeaf(target)
+	if target == nil {
 		return false
 	}
-	return true
+	for ; err != nil; err = errors.Unwrap(err) {
+		leaf := matchLeaf(err)
+		if leaf == nil {
+			continue
+		}
+		if leaf == target || leaf.Error() == target.Error() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLeaf return the error to be compared by Match
+// Errs is only a container, except when it only have codes, then the codes is the error
+func matchLeaf(err error) error {
+	errs, ok := err.(*Errs)
+	if !ok {
+		return err
+	}
+	if errs.err != nil || errs.code == nil {
+		return nil
+	}
+	errString, _ := errs.code.ErrorAndCode()
+	return errors.New(errString)
 }
 
 // Codes is interface to define error custom code.
@@ -187,20 +511,72 @@ type Codes interface {
 	Err() error
 }
 
+var (
+	codesMu       sync.RWMutex
+	codesRegistry = make(map[string]Codes)
+)
+
+// RegisterCodes register codes so it can be found by its identifier
+// this is used to rebuild the codes of error which is decoded from other process
+// the identifier is the result of String() if codes implement fmt.Stringer
+// DefaultCodes is registered by default
+func RegisterCodes(codes ...Codes) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	for _, code := range codes {
+		codesRegistry[codeName(code)] = code
+	}
+}
+
+// LookupCodes return the registered codes by its identifier
+func LookupCodes(name string) (Codes, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	code, ok := codesRegistry[name]
+	return code, ok
+}
+
+func init() {
+	for code := Other; code <= DataLoss; code++ {
+		RegisterCodes(code)
+	}
+}
+
 // Default implementation of Codes
 type DefaultCodes int
 
+// The first five codes are kept in the same order for backward compatibility
+// the rest is a canonical set of codes, inspired by gRPC codes
 const (
 	Other DefaultCodes = iota
 	DatabaseError
 	RedisError
 	ServiceNotAvailableError
 	RequestTimeOutError
+	NotFound
+	InvalidArgument
+	AlreadyExists
+	PermissionDenied
+	Unauthenticated
+	ResourceExhausted
+	FailedPrecondition
+	Conflict
+	Aborted
+	OutOfRange
+	Unavailable
+	DeadlineExceeded
+	Canceled
+	Unimplemented
+	Internal
+	DataLoss
 )
 
+// StatusClientClosedRequest is a non-standard http status used when the request is canceled by the client
+const StatusClientClosedRequest = 499
+
 var _ Codes = (DefaultCodes)(Other)
 
-// ErrorAndCode will return
+// ErrorAndCode will return the error string and http status of the codes
 func (c DefaultCodes) ErrorAndCode() (string, int) {
 	switch c {
 	case Other:
@@ -210,9 +586,41 @@ func (c DefaultCodes) ErrorAndCode() (string, int) {
 	case RedisError:
 		return "Redis error", http.StatusInternalServerError
 	case ServiceNotAvailableError:
-		return "Service not available", http.StatusInternalServerError
+		return "Service not available", http.StatusServiceUnavailable
 	case RequestTimeOutError:
 		return "Request timed out", http.StatusRequestTimeout
+	case NotFound:
+		return "Not found", http.StatusNotFound
+	case InvalidArgument:
+		return "Invalid argument", http.StatusBadRequest
+	case AlreadyExists:
+		return "Already exists", http.StatusConflict
+	case PermissionDenied:
+		return "Permission denied", http.StatusForbidden
+	case Unauthenticated:
+		return "Unauthenticated", http.StatusUnauthorized
+	case ResourceExhausted:
+		return "Resource exhausted", http.StatusTooManyRequests
+	case FailedPrecondition:
+		return "Failed precondition", http.StatusBadRequest
+	case Conflict:
+		return "Conflict", http.StatusConflict
+	case Aborted:
+		return "Aborted", http.StatusConflict
+	case OutOfRange:
+		return "Out of range", http.StatusBadRequest
+	case Unavailable:
+		return "Service unavailable", http.StatusServiceUnavailable
+	case DeadlineExceeded:
+		return "Deadline exceeded", http.StatusGatewayTimeout
+	case Canceled:
+		return "Request canceled", StatusClientClosedRequest
+	case Unimplemented:
+		return "Not implemented", http.StatusNotImplemented
+	case Internal:
+		return "Internal error", http.StatusInternalServerError
+	case DataLoss:
+		return "Data loss", http.StatusInternalServerError
 	default:
 		return "Internal server error", http.StatusInternalServerError
 	}
@@ -222,3 +630,63 @@ func (c DefaultCodes) Err() error {
 	err, _ := c.ErrorAndCode()
 	return New(err)
 }
+
+// Retryable report whether the operation failed with the codes is worth to be retried
+func (c DefaultCodes) Retryable() bool {
+	switch c {
+	case ServiceNotAvailableError, RequestTimeOutError, ResourceExhausted, Aborted, Unavailable, DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// String return the identifier of the codes
+func (c DefaultCodes) String() string {
+	switch c {
+	case Other:
+		return "Other"
+	case DatabaseError:
+		return "DatabaseError"
+	case RedisError:
+		return "RedisError"
+	case ServiceNotAvailableError:
+		return "ServiceNotAvailableError"
+	case RequestTimeOutError:
+		return "RequestTimeOutError"
+	case NotFound:
+		return "NotFound"
+	case InvalidArgument:
+		return "InvalidArgument"
+	case AlreadyExists:
+		return "AlreadyExists"
+	case PermissionDenied:
+		return "PermissionDenied"
+	case Unauthenticated:
+		return "Unauthenticated"
+	case ResourceExhausted:
+		return "ResourceExhausted"
+	case FailedPrecondition:
+		return "FailedPrecondition"
+	case Conflict:
+		return "Conflict"
+	case Aborted:
+		return "Aborted"
+	case OutOfRange:
+		return "OutOfRange"
+	case Unavailable:
+		return "Unavailable"
+	case DeadlineExceeded:
+		return "DeadlineExceeded"
+	case Canceled:
+		return "Canceled"
+	case Unimplemented:
+		return "Unimplemented"
+	case Internal:
+		return "Internal"
+	case DataLoss:
+		return "DataLoss"
+	default:
+		return "DefaultCodes(" + strconv.Itoa(int(c)) + ")"
+	}
+}
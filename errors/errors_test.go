@@ -1,8 +1,13 @@
 package errors
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -65,6 +70,16 @@ func TestMatch(t *testing.T) {
 			err2:        errors.New("Something is different"),
 			expectMatch: false,
 		},
+		{
+			err1:        New(New(New(sql.ErrNoRows), Fields{"order_id": 1})),
+			err2:        sql.ErrNoRows,
+			expectMatch: true,
+		},
+		{
+			err1:        New(New(sql.ErrNoRows)),
+			err2:        New(sql.ErrConnDone),
+			expectMatch: false,
+		},
 	}
 
 	for _, val := range cases {
@@ -73,3 +88,279 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestWrap(t *testing.T) {
+	inner := New(sql.ErrNoRows, Fields{"order_id": 10})
+	err := New(New(inner, []string{"repo"}), []string{"service"})
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expect %v to be found in chain", sql.ErrNoRows)
+	}
+	if errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("Expect %v to not be found in chain", sql.ErrTxDone)
+	}
+	if err.Error() != sql.ErrNoRows.Error() {
+		t.Errorf("Expect %s but got %s", sql.ErrNoRows.Error(), err.Error())
+	}
+	if !reflect.DeepEqual(err.GetFields(), Fields{"order_id": 10}) {
+		t.Errorf("Expect fields to be carried but got %v", err.GetFields())
+	}
+	if len(err.GetMessages()) != 2 {
+		t.Errorf("Expect %d but got %d", 2, len(err.GetMessages()))
+	}
+
+	if msg := New(Op("svc.Get"), inner.WithUserMessage("Order not found")).GetMessage(); msg != "Order not found" {
+		t.Errorf("Expect message to be carried but got %q", msg)
+	}
+
+	var errs *Errs
+	if !errors.As(err, &errs) || errs != err {
+		t.Error("Expect *Errs to be found with As")
+	}
+	if Unwrap(Unwrap(err)) != inner {
+		t.Error("Expect inner error to be found after unwrapping twice")
+	}
+}
+
+func TestIsAndAsCode(t *testing.T) {
+	err := New(New(DatabaseError))
+	if !Is(err, New(DatabaseError)) {
+		t.Error("Expect error with same code to match")
+	}
+	if Is(err, New(RedisError)) {
+		t.Error("Expect error with different code to not match")
+	}
+	orderNotFound := New(NotFound, "order not found")
+	if Is(orderNotFound, New(NotFound, "user not found")) {
+		t.Error("Expect sentinels with the same code to not match")
+	}
+	if !Is(New(Op("order.Get"), orderNotFound), orderNotFound) {
+		t.Error("Expect sentinel to match by identity")
+	}
+
+	var code Codes
+	if !As(err, &code) {
+		t.Fatal("Expect code to be found with As")
+	}
+	if code != DatabaseError {
+		t.Errorf("Expect %v but got %v", DatabaseError, code)
+	}
+
+	// interfaces implemented by the code is looked up in the cause
+	dnsErr := &net.DNSError{Err: "server misbehaving", IsTemporary: true}
+	var temporary interface{ Temporary() bool }
+	if !As(New(DatabaseError, dnsErr), &temporary) || temporary != dnsErr {
+		t.Errorf("Expect %v but got %v", dnsErr, temporary)
+	}
+	var stringer fmt.Stringer
+	if As(New(DatabaseError, "failed"), &stringer) {
+		t.Errorf("Expect code to not be set as fmt.Stringer but got %v", stringer)
+	}
+}
+
+func TestOps(t *testing.T) {
+	dbErr := errors.New("duplicate key")
+	inner := New(Op("repo.Insert"), DatabaseError, dbErr)
+	err := New(Op("order.Create"), inner)
+
+	expect := "order.Create: repo.Insert: Database error: duplicate key"
+	if err.Error() != expect {
+		t.Errorf("Expect %s but got %s", expect, err.Error())
+	}
+	if !reflect.DeepEqual(err.GetOps(), []Op{"order.Create", "repo.Insert"}) {
+		t.Errorf("Expect ops to be walked but got %v", err.GetOps())
+	}
+	if !errors.Is(err, dbErr) {
+		t.Errorf("Expect %v to be found in chain", dbErr)
+	}
+
+	cases := []struct {
+		err    *Errs
+		expect string
+	}{
+		{err: New(), expect: "Unknown error"},
+		{err: New(DatabaseError), expect: "Database error"},
+		{err: New(Op("order.Get"), RedisError), expect: "order.Get: Redis error"},
+		{err: New(Op("order.Get"), DatabaseError, New(DatabaseError, "no rows")), expect: "order.Get: Database error: no rows"},
+	}
+	for _, val := range cases {
+		if val.err.Error() != val.expect {
+			t.Errorf("Expect %s but got %s", val.expect, val.err.Error())
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	if Trace(nil, "order.Get") != nil {
+		t.Error("Expect nil error to stay nil")
+	}
+
+	inner := New(sql.ErrNoRows)
+	err := Trace(inner, "repo.Get")
+	if len(inner.GetTrace()) != 0 {
+		t.Errorf("Expect traced error to be a copy but inner got %v", inner.GetTrace())
+	}
+	err = Trace(New(Op("order.Get"), err), "order.Get")
+	err = Trace(err)
+
+	errs := err.(*Errs)
+	expect := []string{"repo.Get", "order.Get", "errors.TestTrace"}
+	if !reflect.DeepEqual(errs.GetTrace(), expect) {
+		t.Errorf("Expect %v but got %v", expect, errs.GetTrace())
+	}
+
+	err = Trace(sql.ErrNoRows, "repo.Get")
+	if !reflect.DeepEqual(err.(*Errs).GetTrace(), []string{"repo.Get"}) {
+		t.Errorf("Expect standard error to be wrapped but got %v", err)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expect %v to be found in chain", sql.ErrNoRows)
+	}
+}
+
+func TestWithUserMessageShared(t *testing.T) {
+	sentinel := New(NotFound)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if msg := sentinel.WithUserMessage("Order not found").GetMessage(); msg != "Order not found" {
+				t.Errorf("Expect message to be set on the copy but got %q", msg)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = sentinel.MarshalJSON()
+		}()
+	}
+	wg.Wait()
+	if msg := sentinel.GetMessage(); msg != "" {
+		t.Errorf("Expect sentinel to not be changed but got %q", msg)
+	}
+}
+
+func TestWrapWithDetail(t *testing.T) {
+	detailErr := fmt.Errorf("x")
+	cases := []struct {
+		err       *Errs
+		errString string
+		detail    error
+	}{
+		{New(New(NotFound), "order 5 not found"), "order 5 not found: Not found", nil},
+		{New("order 5 not found", New(NotFound)), "order 5 not found: Not found", nil},
+		{New(New(NotFound, Fields{"order_id": 5}), detailErr), "x: Not found", detailErr},
+		{New(detailErr, New(NotFound, Fields{"order_id": 5})), "x: Not found", detailErr},
+	}
+	for _, val := range cases {
+		if val.err.GetCode() != NotFound {
+			t.Errorf("Expect code of the cause to be kept but got %v", val.err.GetCode())
+		}
+		if status := HTTPStatus(val.err); status != http.StatusNotFound {
+			t.Errorf("Expect %d but got %d", http.StatusNotFound, status)
+		}
+		if val.err.Error() != val.errString {
+			t.Errorf("Expect %s but got %s", val.errString, val.err.Error())
+		}
+		if val.detail != nil && !Is(val.err, val.detail) {
+			t.Errorf("Expect %v to be found in chain", val.detail)
+		}
+	}
+
+	decoded := new(Errs)
+	b, _ := New(New(NotFound), "order 5 not found").MarshalJSON()
+	if err := decoded.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Error() != "order 5 not found: Not found" || decoded.GetCode() != NotFound {
+		t.Errorf("Expect detail and code to be decoded but got %v", decoded)
+	}
+}
+
+func TestTraceShared(t *testing.T) {
+	sentinel := New(NotFound)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if traces := Trace(sentinel, "fn").(*Errs).GetTrace(); !reflect.DeepEqual(traces, []string{"fn"}) {
+				t.Errorf("Expect single trace but got %v", traces)
+			}
+		}()
+	}
+	wg.Wait()
+	if traces := sentinel.GetTrace(); len(traces) != 0 {
+		t.Errorf("Expect sentinel to not be traced but got %v", traces)
+	}
+}
+
+func TestDefaultCodes(t *testing.T) {
+	cases := []struct {
+		code      DefaultCodes
+		name      string
+		httpCode  int
+		retryable bool
+	}{
+		{code: Other, name: "Other", httpCode: http.StatusInternalServerError},
+		{code: DatabaseError, name: "DatabaseError", httpCode: http.StatusInternalServerError},
+		{code: ServiceNotAvailableError, name: "ServiceNotAvailableError", httpCode: http.StatusServiceUnavailable, retryable: true},
+		{code: RequestTimeOutError, name: "RequestTimeOutError", httpCode: http.StatusRequestTimeout, retryable: true},
+		{code: NotFound, name: "NotFound", httpCode: http.StatusNotFound},
+		{code: InvalidArgument, name: "InvalidArgument", httpCode: http.StatusBadRequest},
+		{code: AlreadyExists, name: "AlreadyExists", httpCode: http.StatusConflict},
+		{code: PermissionDenied, name: "PermissionDenied", httpCode: http.StatusForbidden},
+		{code: Unauthenticated, name: "Unauthenticated", httpCode: http.StatusUnauthorized},
+		{code: ResourceExhausted, name: "ResourceExhausted", httpCode: http.StatusTooManyRequests, retryable: true},
+		{code: FailedPrecondition, name: "FailedPrecondition", httpCode: http.StatusBadRequest},
+		{code: Conflict, name: "Conflict", httpCode: http.StatusConflict},
+		{code: Unavailable, name: "Unavailable", httpCode: http.StatusServiceUnavailable, retryable: true},
+		{code: DeadlineExceeded, name: "DeadlineExceeded", httpCode: http.StatusGatewayTimeout, retryable: true},
+		{code: Canceled, name: "Canceled", httpCode: StatusClientClosedRequest},
+		{code: DefaultCodes(100), name: "DefaultCodes(100)", httpCode: http.StatusInternalServerError},
+	}
+
+	for _, val := range cases {
+		if _, httpCode := val.code.ErrorAndCode(); httpCode != val.httpCode {
+			t.Errorf("%s: Expect %d but got %d", val.name, val.httpCode, httpCode)
+		}
+		if val.code.Retryable() != val.retryable {
+			t.Errorf("%s: Expect retryable %v but got %v", val.name, val.retryable, val.code.Retryable())
+		}
+		if val.code.String() != val.name {
+			t.Errorf("Expect %s but got %s", val.name, val.code.String())
+		}
+	}
+}
+
+func TestMatchTemplate(t *testing.T) {
+	dbErr := errors.New("duplicate key")
+	err := New(Op("order.Create"), New(Op("repo.Insert"), DatabaseError, dbErr, Fields{"order_id": 10, "table": "orders"}))
+
+	cases := []struct {
+		template    error
+		expectMatch bool
+	}{
+		{template: New(DatabaseError), expectMatch: true},
+		{template: New(RedisError), expectMatch: false},
+		{template: New(Op("repo.Insert")), expectMatch: true},
+		{template: New(Op("repo.Get")), expectMatch: false},
+		{template: New(Fields{"table": "orders"}), expectMatch: true},
+		{template: New(Fields{"table": "users"}), expectMatch: false},
+		{template: New(Op("order.Create"), DatabaseError, Fields{"order_id": 10}), expectMatch: true},
+		{template: New(Op("order.Create"), NotFound, Fields{"order_id": 10}), expectMatch: false},
+		{template: New(dbErr), expectMatch: true},
+		{template: New(errors.New("duplicate key")), expectMatch: true},
+		{template: New(New(Op("repo.Insert"), DatabaseError)), expectMatch: true},
+		{template: New(New(Op("repo.Get"))), expectMatch: false},
+		{template: New(New(New(dbErr))), expectMatch: true},
+		{template: dbErr, expectMatch: true},
+		{template: sql.ErrNoRows, expectMatch: false},
+	}
+
+	for _, val := range cases {
+		if match := Match(val.template, err); match != val.expectMatch {
+			t.Errorf("TestMatchTemplate: %v Expecting %v but got %v", val.template, val.expectMatch, match)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package errors
+
+// MergeMode decide which value is kept when fields with the same key are merged
+type MergeMode int
+
+const (
+	// MergeOverride replace the existing value with the new value
+	MergeOverride MergeMode = iota
+	// MergeKeepFirst keep the existing value, the new value is dropped
+	MergeKeepFirst
+	// MergeAppend collect both values into []interface{}, the existing value first
+	// an existing []interface{} is extended instead of nested
+	MergeAppend
+)
+
+// Copy return a shallow copy of fields, nil is returned if fields is nil
+func (f Fields) Copy() Fields {
+	if f == nil {
+		return nil
+	}
+	fields := make(Fields, len(f))
+	for k, v := range f {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Merge return a new fields of f and other, neither f nor other is changed
+// mode decide which value is kept when both have the same key
+func (f Fields) Merge(other Fields, mode MergeMode) Fields {
+	if len(other) == 0 {
+		return f.Copy()
+	}
+	fields := make(Fields, len(f)+len(other))
+	for k, v := range f {
+		fields[k] = v
+	}
+	for k, v := range other {
+		existing, ok := fields[k]
+		if !ok {
+			fields[k] = v
+			continue
+		}
+		switch mode {
+		case MergeKeepFirst:
+		case MergeAppend:
+			fields[k] = appendValue(existing, v)
+		default:
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// appendValue always create a new slice, so the slice of the existing value is not aliased
+func appendValue(existing, v interface{}) []interface{} {
+	values, ok := existing.([]interface{})
+	if !ok {
+		return []interface{}{existing, v}
+	}
+	return append(append(make([]interface{}, 0, len(values)+1), values...), v)
+}
+
+// AddFields return a copy of the error with fields merged into its fields
+// the error itself is not changed, so it is safe to be called on a shared error
+func (e *Errs) AddFields(fields Fields, mode MergeMode) *Errs {
+	copied := e.copy()
+	copied.fields = e.fields.Merge(fields, mode)
+	return copied
+}
+
+// AddMessages return a copy of the error with msgs appended to its messages
+// the messages of the error itself is not changed
+func (e *Errs) AddMessages(msgs ...string) *Errs {
+	copied := e.copy()
+	copied.messages = appendMessages(e.messages, msgs)
+	return copied
+}
+
+// copy return a shallow copy of the error
+// capacity of traces is limited, so Trace on the copy doesn't write into the traces of the original
+func (e *Errs) copy() *Errs {
+	copied := *e
+	copied.traces = e.traces[:len(e.traces):len(e.traces)]
+	return &copied
+}
+
+// appendMessages always create a new slice, so appending to a copied error doesn't write into the original
+func appendMessages(messages, msgs []string) []string {
+	if len(messages)+len(msgs) == 0 {
+		return messages
+	}
+	return append(append(make([]string, 0, len(messages)+len(msgs)), messages...), msgs...)
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFieldsMerge(t *testing.T) {
+	cases := []struct {
+		mode   MergeMode
+		expect Fields
+	}{
+		{MergeOverride, Fields{"order_id": 2, "user_id": 1, "table": "orders"}},
+		{MergeKeepFirst, Fields{"order_id": 1, "user_id": 1, "table": "orders"}},
+		{MergeAppend, Fields{"order_id": []interface{}{1, 2}, "user_id": 1, "table": "orders"}},
+	}
+	for _, c := range cases {
+		first := Fields{"order_id": 1, "user_id": 1}
+		merged := first.Merge(Fields{"order_id": 2, "table": "orders"}, c.mode)
+		if !reflect.DeepEqual(merged, c.expect) {
+			t.Errorf("Expect %v with mode %d but got %v", c.expect, c.mode, merged)
+		}
+		if !reflect.DeepEqual(first, Fields{"order_id": 1, "user_id": 1}) {
+			t.Errorf("Expect fields to not be changed by Merge but got %v", first)
+		}
+	}
+
+	appended := Fields{"retry": []interface{}{1, 2}}.Merge(Fields{"retry": 3}, MergeAppend)
+	if !reflect.DeepEqual(appended, Fields{"retry": []interface{}{1, 2, 3}}) {
+		t.Errorf("Expect existing values to be extended but got %v", appended)
+	}
+}
+
+func TestNewMergeFields(t *testing.T) {
+	inner := New(NotFound, Fields{"order_id": 10, "table": "orders"})
+	err := New(Fields{"order_id": 11}, inner, Fields{"user_id": 1})
+	expect := Fields{"order_id": 11, "table": "orders", "user_id": 1}
+	if !reflect.DeepEqual(err.GetFields(), expect) {
+		t.Errorf("Expect fields %v but got %v", expect, err.GetFields())
+	}
+	if !reflect.DeepEqual(inner.GetFields(), Fields{"order_id": 10, "table": "orders"}) {
+		t.Errorf("Expect fields of inner error to not be changed but got %v", inner.GetFields())
+	}
+
+	fields := Fields{"order_id": 10}
+	err = NewWith(WithFields(fields))
+	fields["order_id"] = 11
+	err.GetFields()["order_id"] = 12
+	if !reflect.DeepEqual(err.GetFields(), Fields{"order_id": 10}) {
+		t.Errorf("Expect fields to be copied but got %v", err.GetFields())
+	}
+}
+
+func TestAddMessages(t *testing.T) {
+	err := New("error", []string{"first"}).AddMessages("second")
+	copy1 := err.AddMessages("third")
+	copy2 := err.AddMessages("fourth")
+	if !reflect.DeepEqual(err.GetMessages(), []string{"first", "second"}) {
+		t.Errorf("Expect messages of the original error to not be changed but got %v", err.GetMessages())
+	}
+	if !reflect.DeepEqual(copy1.GetMessages(), []string{"first", "second", "third"}) {
+		t.Errorf("Expect messages to be appended but got %v", copy1.GetMessages())
+	}
+	if !reflect.DeepEqual(copy2.GetMessages(), []string{"first", "second", "fourth"}) {
+		t.Errorf("Expect messages to be appended but got %v", copy2.GetMessages())
+	}
+	_ = append(err.GetMessages(), "appended")
+	if len(err.GetMessages()) != 2 {
+		t.Errorf("Expect messages to not be changed by appending to GetMessages but got %v", err.GetMessages())
+	}
+}
+
+func TestAddFieldsConcurrent(t *testing.T) {
+	err := New(NotFound, Fields{"order_id": 10})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			copied := err.AddFields(Fields{"worker": i}, MergeOverride)
+			_ = New(Op("worker.Run"), copied, Fields{"order_id": i}).GetRedactedFields()
+		}(i)
+	}
+	wg.Wait()
+	if !reflect.DeepEqual(err.GetFields(), Fields{"order_id": 10}) {
+		t.Errorf("Expect fields to not be changed but got %v", err.GetFields())
+	}
+}
@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint return a stable identifier of the error, to group identical failures
+// the fingerprint is derived from codes, op chain, the call site where the innermost Errs is created
+// and the type of the underlying error, the message is not used as it usually contain variable parts
+// the call site is only used when it is recorded by the capture policy and it is not sampled,
+// errors which are sampled or not captured use codes, op chain and the type of the underlying error
+// if there is no codes and no call site, like errors decoded from other process, the message is used
+// after numbers and quoted strings are removed
+func (e *Errs) Fingerprint() string {
+	var (
+		parts []string
+		site  string
+		cause error
+	)
+	if code := e.GetCode(); code != nil {
+		parts = append(parts, "code="+codeName(code))
+	}
+	for _, op := range e.GetOps() {
+		parts = append(parts, "op="+string(op))
+	}
+	for err := error(e); err != nil; err = Unwrap(err) {
+		errs, ok := err.(*Errs)
+		if !ok {
+			cause = err
+			break
+		}
+		if file, line := errs.GetFileAndLine(); line != 0 && !errs.sampled {
+			site = shortFile(file) + ":" + strconv.Itoa(line)
+		}
+	}
+	if site != "" {
+		parts = append(parts, "site="+site)
+	}
+	if cause != nil {
+		parts = append(parts, "cause="+reflect.TypeOf(cause).String())
+	}
+	if e.GetCode() == nil && site == "" {
+		parts = append(parts, "msg="+normalizeMessage(e.Error()))
+	}
+	return hash(parts)
+}
+
+// Fingerprint return fingerprint of the error
+// error which is not *Errs is fingerprinted by its type and normalized message
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	var errs *Errs
+	if As(err, &errs) {
+		return errs.Fingerprint()
+	}
+	return hash([]string{"cause=" + reflect.TypeOf(err).String(), "msg=" + normalizeMessage(err.Error())})
+}
+
+func hash(parts []string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// shortFile return the last directory and file name, so the fingerprint is the same across build environment
+func shortFile(file string) string {
+	slash := strings.LastIndex(file, "/")
+	if slash <= 0 {
+		return file
+	}
+	if dir := strings.LastIndex(file[:slash], "/"); dir >= 0 {
+		return file[dir+1:]
+	}
+	return file
+}
+
+var (
+	quotedPattern = regexp.MustCompile("'[^']*'|\"[^\"]*\"|`[^`]*`")
+	numberPattern = regexp.MustCompile(`0x[0-9a-fA-F]+|[0-9a-fA-F]{8}(-[0-9a-fA-F]{4}){3}-[0-9a-fA-F]{12}|\d+(\.\d+)?`)
+)
+
+// normalizeMessage remove the variable parts of the message such as quoted strings, numbers and uuid
+func normalizeMessage(msg string) string {
+	msg = quotedPattern.ReplaceAllString(msg, "?")
+	return numberPattern.ReplaceAllString(msg, "?")
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func insertOrder(id int) *Errs {
+	return New(Op("repo.Insert"), DatabaseError, errors.New("duplicate key "+string(rune('0'+id))))
+}
+
+func TestFingerprint(t *testing.T) {
+	SetRuntimeOutput(true)
+	defer SetRuntimeOutput(false)
+
+	err1 := New(Op("order.Create"), insertOrder(1))
+	err2 := New(Op("order.Create"), insertOrder(2))
+	if err1.Fingerprint() != err2.Fingerprint() {
+		t.Errorf("Expect same fingerprint for the same failure but got %s and %s", err1.Fingerprint(), err2.Fingerprint())
+	}
+	if len(err1.Fingerprint()) != 16 {
+		t.Errorf("Expect 16 characters fingerprint but got %s", err1.Fingerprint())
+	}
+
+	different := []*Errs{
+		New(Op("order.Update"), insertOrder(1)),
+		New(Op("order.Create"), New(Op("repo.Insert"), RedisError, errors.New("duplicate key 1"))),
+		New(Op("order.Create"), New(Op("repo.Insert"), DatabaseError, errors.New("duplicate key 1"))),
+	}
+	for _, err := range different {
+		if err.Fingerprint() == err1.Fingerprint() {
+			t.Errorf("Expect different fingerprint for %v", err)
+		}
+	}
+}
+
+func TestFingerprintMessage(t *testing.T) {
+	err1 := New(`order "A-1" is locked for 10 seconds`)
+	err2 := New(`order "B-2" is locked for 20 seconds`)
+	if err1.Fingerprint() != err2.Fingerprint() {
+		t.Errorf("Expect variable parts of message to be ignored but got %s and %s", err1.Fingerprint(), err2.Fingerprint())
+	}
+	if Fingerprint(err1) != err1.Fingerprint() {
+		t.Errorf("Expect %s but got %s", err1.Fingerprint(), Fingerprint(err1))
+	}
+	if Fingerprint(errors.New("timeout after 10s")) != Fingerprint(errors.New("timeout after 20s")) {
+		t.Error("Expect standard errors to be fingerprinted by normalized message")
+	}
+	if Fingerprint(nil) != "" {
+		t.Errorf("Expect empty fingerprint but got %s", Fingerprint(nil))
+	}
+}
+
+func TestFingerprintSampled(t *testing.T) {
+	SetCapturePolicy(CapturePolicy{ServerError: CaptureSampled, SampleRate: 0.5})
+	defer SetCapturePolicy(CapturePolicy{})
+
+	fingerprints := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		fingerprints[New(DatabaseError, "x").Fingerprint()] = true
+	}
+	if len(fingerprints) != 1 {
+		t.Errorf("Expect the same fingerprint regardless of sampling but got %d fingerprints", len(fingerprints))
+	}
+}
@@ -0,0 +1,66 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// HookEvent is the moment when hooks is called
+type HookEvent int
+
+const (
+	// HookCreate is called when *Errs is created by New, NewCtx, NewWith or WithCodes
+	// wrapping an error create a new *Errs, so one failure can be seen more than once
+	HookCreate HookEvent = iota + 1
+	// HookRender is called for every error written to http response by WriteHTTP and ToHTTPResponse
+	HookRender
+	// HookLog is called when the error is logged by logger.Errors
+	HookLog
+)
+
+// String return the lowercase name of the event
+func (e HookEvent) String() string {
+	switch e {
+	case HookCreate:
+		return "create"
+	case HookRender:
+		return "render"
+	case HookLog:
+		return "log"
+	default:
+		return "unknown"
+	}
+}
+
+// Hook is called with the event and the error, it should be fast and must not modify the error
+// error of HookRender and HookLog is not always *Errs
+type Hook func(event HookEvent, err error)
+
+var (
+	hooksMu sync.Mutex
+	// hooks hold []Hook, it is read on every New so atomic.Value is used instead of lock
+	hooks atomic.Value
+)
+
+// RegisterHook register hook to be called on every event, it is used for instrumentation like errmetrics
+func RegisterHook(h Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	current, _ := hooks.Load().([]Hook)
+	// copy on write, so running hooks is not affected
+	next := make([]Hook, len(current), len(current)+1)
+	copy(next, current)
+	hooks.Store(append(next, h))
+}
+
+// RunHooks call the registered hooks with the event
+// it is used by packages which render the error by itself, like logger
+func RunHooks(event HookEvent, err error) {
+	if err == nil {
+		return
+	}
+	current, _ := hooks.Load().([]Hook)
+	for _, h := range current {
+		h(event, err)
+	}
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunHooks(t *testing.T) {
+	var events []HookEvent
+	// hooks cannot be removed, so only errors with this op is recorded
+	RegisterHook(func(event HookEvent, err error) {
+		var errs *Errs
+		if As(err, &errs) && errs.GetOp() == "hook.Test" {
+			events = append(events, event)
+		}
+	})
+	err := New(Op("hook.Test"), NotFound)
+	RunHooks(HookLog, err)
+	RunHooks(HookLog, nil)
+	if err := WriteHTTP(httptest.NewRecorder(), err); err != nil {
+		t.Fatal(err)
+	}
+	RunHooks(HookRender, errors.New("plain"))
+
+	expect := []HookEvent{HookCreate, HookLog, HookRender}
+	if len(events) != len(expect) {
+		t.Fatalf("Expect %v but got %v", expect, events)
+	}
+	for i := range expect {
+		if events[i] != expect[i] {
+			t.Errorf("Expect %v at %d but got %v", expect[i], i, events[i])
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPError is the JSON representation of an error in http response
+// messages added by AddMessages are internal, so they are not written and dropped from the response
+type HTTPError struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	UserMessage string `json:"user_message,omitempty"`
+	Fields      Fields `json:"fields,omitempty"`
+}
+
+// HTTPResponse is the JSON envelope of errors in http response
+type HTTPResponse struct {
+	Errors []HTTPError `json:"errors"`
+}
+
+// HTTPStatus return http status of the error based on its codes
+// error without codes is treated as internal server error
+// the overall status is returned for MultiErrs and errors created by errors.Join
+func HTTPStatus(err error) int {
+	if errs, ok := multiErrors(err); ok {
+		return NewMulti(errs...).HTTPStatus()
+	}
+	code := getCode(err)
+	if code == nil {
+		return http.StatusInternalServerError
+	}
+	_, httpCode := code.ErrorAndCode()
+	return httpCode
+}
+
+// ToHTTPResponse return http status and JSON envelope of the error
+// fields is the allowed list of Fields key to be shown in the response, other fields are not shown
+// the allowed fields is still redacted by the redact policy
+// error without codes is hidden behind a generic internal server error
+// the user message is localized to the fallback language of DefaultCatalog if it is available
+// every error in MultiErrs and errors created by errors.Join is listed in the envelope
+func ToHTTPResponse(err error, fields ...string) (int, HTTPResponse) {
+	return toHTTPResponse(err, "", fields)
+}
+
+// ToHTTPResponseLang return http status and JSON envelope of the error like ToHTTPResponse
+// the user message is localized by DefaultCatalog, lang can be a language tag or Accept-Language header
+func ToHTTPResponseLang(err error, lang string, fields ...string) (int, HTTPResponse) {
+	return toHTTPResponse(err, lang, fields)
+}
+
+func toHTTPResponse(err error, lang string, fields []string) (int, HTTPResponse) {
+	errs, ok := multiErrors(err)
+	if !ok {
+		RunHooks(HookRender, err)
+		return HTTPStatus(err), HTTPResponse{Errors: []HTTPError{toHTTPError(err, lang, fields)}}
+	}
+	multi := NewMulti(errs...)
+	resp := HTTPResponse{Errors: make([]HTTPError, 0, multi.Len())}
+	for _, e := range multi.Errors() {
+		RunHooks(HookRender, e)
+		resp.Errors = append(resp.Errors, toHTTPError(e, lang, fields))
+	}
+	return multi.HTTPStatus(), resp
+}
+
+// WriteHTTP write the error to http response as JSON
+// nothing is written if err is nil
+func WriteHTTP(w http.ResponseWriter, err error, fields ...string) error {
+	if err == nil {
+		return nil
+	}
+	status, resp := toHTTPResponse(err, "", fields)
+	return writeHTTP(w, status, resp)
+}
+
+// WriteHTTPLang write the error to http response as JSON like WriteHTTP
+// the user message is localized by DefaultCatalog, lang can be a language tag or Accept-Language header
+//
+//	errors.WriteHTTPLang(w, err, r.Header.Get("Accept-Language"))
+func WriteHTTPLang(w http.ResponseWriter, err error, lang string, fields ...string) error {
+	if err == nil {
+		return nil
+	}
+	status, resp := toHTTPResponse(err, lang, fields)
+	return writeHTTP(w, status, resp)
+}
+
+func writeHTTP(w http.ResponseWriter, status int, resp HTTPResponse) error {
+	jsonResp, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(jsonResp)
+	return err
+}
+
+func toHTTPError(err error, lang string, fields []string) HTTPError {
+	code := getCode(err)
+	if code == nil {
+		code = Other
+	}
+	errString, _ := code.ErrorAndCode()
+	httpErr := HTTPError{
+		Code:    codeName(code),
+		Message: errString,
+	}
+
+	// localized message take precedence, the raw message is used if the catalog doesn't have it
+	// empty lang still use the fallback language of the catalog
+	httpErr.UserMessage, _ = DefaultCatalog.Message(err, lang)
+	var errs *Errs
+	if !As(err, &errs) {
+		return httpErr
+	}
+	if httpErr.UserMessage == "" {
+		httpErr.UserMessage = errs.userMessage()
+	}
+	errFields := errs.GetRedactedFields()
+	for _, key := range fields {
+		value, ok := errFields[key]
+		if !ok {
+			continue
+		}
+		if httpErr.Fields == nil {
+			httpErr.Fields = make(Fields)
+		}
+		httpErr.Fields[key] = value
+	}
+	return httpErr
+}
+
+// getCode return codes of the error, nil if there is no *Errs in the error chain
+func getCode(err error) Codes {
+	var errs *Errs
+	if !As(err, &errs) {
+		return nil
+	}
+	return errs.GetCode()
+}
+
+// codeName return the identifier of the codes
+func codeName(code Codes) string {
+	if s, ok := code.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(code)
+}
+
+// userMessage return the outermost message set by SetMessage in the error chain
+func (e *Errs) userMessage() string {
+	for err := error(e); err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok && errs.message != "" {
+			return errs.message
+		}
+	}
+	return ""
+}
+
+// RemoteCodes is codes decoded from other process which is not registered by RegisterCodes
+// the identifier, message and http status are kept, so the error is still classified correctly
+type RemoteCodes struct {
+	Name       string
+	Message    string
+	HTTPStatus int
+}
+
+var _ Codes = RemoteCodes{}
+
+// ErrorAndCode return the message and http status of the remote codes
+func (c RemoteCodes) ErrorAndCode() (string, int) {
+	return c.Message, c.HTTPStatus
+}
+
+func (c RemoteCodes) Err() error {
+	return New(c.Message)
+}
+
+// String return the identifier of the remote codes
+func (c RemoteCodes) String() string {
+	return c.Name
+}
+
+// maxErrorBodySize is the maximum size of http response body read by FromHTTPResponse
+const maxErrorBodySize = 1 << 20
+
+// FromHTTPResponse rebuild *Errs from http response written by WriteHTTP
+// codes is looked up from registered codes, and RemoteCodes is used if the codes is not registered
+// if the response is not a JSON envelope, the codes is chosen from the http status
+// only the first error of the envelope is returned, use FromHTTPResponseAll for response of MultiErrs
+// nil is returned if http status is not an error status
+// the body of the response is read, but not closed
+func FromHTTPResponse(resp *http.Response) *Errs {
+	errs := decodeHTTPResponse(resp)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs[0]
+}
+
+// FromHTTPResponseAll rebuild the error like FromHTTPResponse, but every error of the envelope is kept
+// *MultiErrs is returned if the envelope have more than one error, otherwise *Errs is returned
+// RemoteCodes of each error use the overall http status, as the envelope doesn't have status of each error
+func FromHTTPResponseAll(resp *http.Response) error {
+	errs := decodeHTTPResponse(resp)
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	multi := NewMulti()
+	for _, err := range errs {
+		multi.Add(err)
+	}
+	return multi
+}
+
+// decodeHTTPResponse is called by FromHTTPResponse and FromHTTPResponseAll
+// the caller of them is recorded as where the errors is created
+func decodeHTTPResponse(resp *http.Response) []*Errs {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return []*Errs{NewWith(WithCode(codeFromHTTPStatus(resp.StatusCode)), Wrap(err), WithCallerSkip(2))}
+	}
+
+	var envelope HTTPResponse
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Errors) == 0 {
+		errString := strings.TrimSpace(string(body))
+		if errString == "" {
+			errString = http.StatusText(resp.StatusCode)
+		}
+		return []*Errs{NewWith(WithCode(codeFromHTTPStatus(resp.StatusCode)), WithString(errString), WithCallerSkip(2))}
+	}
+	errs := make([]*Errs, 0, len(envelope.Errors))
+	for _, httpErr := range envelope.Errors {
+		errs = append(errs, fromHTTPError(httpErr, resp.StatusCode))
+	}
+	return errs
+}
+
+func fromHTTPError(httpErr HTTPError, status int) *Errs {
+	code, ok := LookupCodes(httpErr.Code)
+	if !ok {
+		code = RemoteCodes{Name: httpErr.Code, Message: httpErr.Message, HTTPStatus: status}
+	}
+	return NewWith(WithCode(code), WithFields(httpErr.Fields), WithUserMessage(httpErr.UserMessage), WithCallerSkip(3))
+}
+
+// codeFromHTTPStatus return DefaultCodes which is the closest to http status
+func codeFromHTTPStatus(status int) Codes {
+	switch status {
+	case http.StatusBadRequest:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusRequestTimeout:
+		return RequestTimeOutError
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusTooManyRequests:
+		return ResourceExhausted
+	case StatusClientClosedRequest:
+		return Canceled
+	case http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	default:
+		return RemoteCodes{Name: strconv.Itoa(status), Message: http.StatusText(status), HTTPStatus: status}
+	}
+}
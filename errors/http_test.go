@@ -0,0 +1,221 @@
+package errors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	withMessage := New(NotFound, sql.ErrNoRows, Fields{"order_id": 10, "query": "select 1"})
+	withMessage.SetMessage("Order is not found")
+
+	cases := []struct {
+		err    error
+		fields []string
+		status int
+		expect HTTPResponse
+	}{
+		{
+			err:    New(Op("order.Get"), withMessage),
+			fields: []string{"order_id", "user_id"},
+			status: http.StatusNotFound,
+			expect: HTTPResponse{Errors: []HTTPError{
+				{Code: "NotFound", Message: "Not found", UserMessage: "Order is not found", Fields: Fields{"order_id": float64(10)}},
+			}},
+		},
+		{
+			err:    New(InvalidArgument, "order_id is empty", Fields{"order_id": 10}),
+			status: http.StatusBadRequest,
+			expect: HTTPResponse{Errors: []HTTPError{
+				{Code: "InvalidArgument", Message: "Invalid argument"},
+			}},
+		},
+		{
+			err:    New("pq: duplicate key"),
+			status: http.StatusInternalServerError,
+			expect: HTTPResponse{Errors: []HTTPError{
+				{Code: "Other", Message: "Internal server error"},
+			}},
+		},
+		{
+			err:    sql.ErrConnDone,
+			status: http.StatusInternalServerError,
+			expect: HTTPResponse{Errors: []HTTPError{
+				{Code: "Other", Message: "Internal server error"},
+			}},
+		},
+	}
+
+	for _, val := range cases {
+		w := httptest.NewRecorder()
+		if err := WriteHTTP(w, val.err, val.fields...); err != nil {
+			t.Fatalf("Expect no error but got %v", err)
+		}
+		if w.Code != val.status {
+			t.Errorf("Expect %d but got %d", val.status, w.Code)
+		}
+		if w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expect json content type but got %s", w.Header().Get("Content-Type"))
+		}
+		var resp HTTPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Expect valid json but got %v", err)
+		}
+		if !reflect.DeepEqual(resp, val.expect) {
+			t.Errorf("Expect %+v but got %+v", val.expect, resp)
+		}
+	}
+}
+
+func TestWriteHTTPNil(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := WriteHTTP(w, nil); err != nil {
+		t.Fatalf("Expect no error but got %v", err)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expect nothing to be written but got %s", w.Body.String())
+	}
+}
+
+type testCodes int
+
+func (c testCodes) ErrorAndCode() (string, int) { return "Order is locked", http.StatusLocked }
+func (c testCodes) Err() error                  { return New(c) }
+func (c testCodes) String() string              { return "OrderLocked" }
+
+func TestFromHTTPResponse(t *testing.T) {
+	notFound := New(Op("order.Get"), NotFound, sql.ErrNoRows, Fields{"order_id": 10})
+	notFound.SetMessage("Order is not found")
+
+	cases := []struct {
+		handler     http.HandlerFunc
+		code        Codes
+		userMessage string
+		fields      Fields
+		errString   string
+	}{
+		{
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				WriteHTTP(w, notFound, "order_id")
+			},
+			code:        NotFound,
+			userMessage: "Order is not found",
+			fields:      Fields{"order_id": float64(10)},
+			errString:   "Not found",
+		},
+		{
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				WriteHTTP(w, New(testCodes(1)))
+			},
+			code:      RemoteCodes{Name: "OrderLocked", Message: "Order is locked", HTTPStatus: http.StatusLocked},
+			errString: "Order is locked",
+		},
+		{
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("Service unavailable\n"))
+			},
+			code:      Unavailable,
+			errString: "Service unavailable: Service unavailable",
+		},
+		{
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			code:      RemoteCodes{Name: "502", Message: "Bad Gateway", HTTPStatus: http.StatusBadGateway},
+			errString: "Bad Gateway: Bad Gateway",
+		},
+	}
+
+	for _, val := range cases {
+		server := httptest.NewServer(val.handler)
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expect no error but got %v", err)
+		}
+		errs := FromHTTPResponse(resp)
+		resp.Body.Close()
+		server.Close()
+
+		if errs == nil {
+			t.Fatal("Expect error but got nil")
+		}
+		if errs.GetCode() != val.code {
+			t.Errorf("Expect %v but got %v", val.code, errs.GetCode())
+		}
+		if errs.GetMessage() != val.userMessage {
+			t.Errorf("Expect %s but got %s", val.userMessage, errs.GetMessage())
+		}
+		if !reflect.DeepEqual(errs.GetFields(), val.fields) {
+			t.Errorf("Expect %v but got %v", val.fields, errs.GetFields())
+		}
+		if errs.Error() != val.errString {
+			t.Errorf("Expect %s but got %s", val.errString, errs.Error())
+		}
+	}
+}
+
+func TestFromHTTPResponseCreate(t *testing.T) {
+	SetRuntimeOutput(true)
+	defer SetRuntimeOutput(false)
+
+	var created int
+	// hooks cannot be removed, so only errors with this field is counted
+	RegisterHook(func(event HookEvent, err error) {
+		var errs *Errs
+		if event == HookCreate && As(err, &errs) && errs.GetFields()["trace_id"] == "from-http" {
+			created++
+		}
+	})
+	w := httptest.NewRecorder()
+	WriteHTTP(w, New(NotFound, Fields{"trace_id": "from-http"}), "trace_id")
+	created = 0
+
+	errs := FromHTTPResponse(w.Result())
+	if created != 1 {
+		t.Errorf("Expect %d created errors but got %d", 1, created)
+	}
+	if file, _ := errs.GetFileAndLine(); !strings.HasSuffix(file, "http_test.go") {
+		t.Errorf("Expect the caller of FromHTTPResponse to be recorded but got %s", file)
+	}
+	resp := &http.Response{StatusCode: http.StatusBadGateway, Body: io.NopCloser(strings.NewReader("bad gateway"))}
+	if file, _ := FromHTTPResponse(resp).GetFileAndLine(); !strings.HasSuffix(file, "http_test.go") {
+		t.Errorf("Expect the caller of FromHTTPResponse to be recorded but got %s", file)
+	}
+}
+
+func TestFromHTTPResponseSuccess(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusOK}
+	if err := FromHTTPResponse(resp); err != nil {
+		t.Errorf("Expect nil but got %v", err)
+	}
+	if err := FromHTTPResponseAll(resp); err != nil {
+		t.Errorf("Expect nil but got %v", err)
+	}
+}
+
+func TestFromHTTPResponseAll(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteHTTP(w, NewMulti(New(InvalidArgument), New(NotFound)))
+	err := FromHTTPResponseAll(w.Result())
+
+	multi, ok := err.(*MultiErrs)
+	if !ok {
+		t.Fatalf("Expect *MultiErrs but got %T", err)
+	}
+	if codes := multi.Codes(); !reflect.DeepEqual(codes, []Codes{InvalidArgument, NotFound}) {
+		t.Errorf("Expect both codes to be decoded but got %v", codes)
+	}
+
+	w = httptest.NewRecorder()
+	WriteHTTP(w, New(NotFound))
+	if errs, ok := FromHTTPResponseAll(w.Result()).(*Errs); !ok || errs.GetCode() != NotFound {
+		t.Errorf("Expect single *Errs but got %v", errs)
+	}
+}
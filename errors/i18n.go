@@ -0,0 +1,224 @@
+package errors
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Catalog hold localized user messages keyed by language and message key
+// message key is the identifier of codes, or the message set by SetMessage
+// message can have placeholders like {order_id} which is filled from fields of the error
+type Catalog struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[string]string
+}
+
+// DefaultCatalog is the catalog used by UserMessage, WriteHTTP and WriteHTTPLang
+var DefaultCatalog = NewCatalog("en")
+
+// NewCatalog create Catalog, fallback is the language used when the requested language is not available
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: normalizeLang(fallback),
+		messages: make(map[string]map[string]string),
+	}
+}
+
+// Add messages of a language to the catalog, messages is keyed by message key
+func (c *Catalog) Add(lang string, messages map[string]string) {
+	lang = normalizeLang(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]string, len(messages))
+	}
+	for key, message := range messages {
+		c.messages[lang][key] = message
+	}
+}
+
+// LoadFS load JSON files matched by pattern from fsys, it can be used with embed.FS
+// the name of the file without extension is the language, for example en.json or id-ID.json
+// the file contain an object of message key and message
+//
+//	{"NotFound": "Order {order_id} is not found"}
+func (c *Catalog) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(b, &messages); err != nil {
+			return New(Op("errors.LoadFS"), err, Fields{"file": file})
+		}
+		c.Add(strings.TrimSuffix(path.Base(file), path.Ext(file)), messages)
+	}
+	return nil
+}
+
+// LoadFiles load JSON files matched by pattern from file system, see LoadFS for the format of the file
+func (c *Catalog) LoadFiles(pattern string) error {
+	dir, base := filepath.Split(pattern)
+	if dir == "" {
+		dir = "."
+	}
+	return c.LoadFS(os.DirFS(dir), base)
+}
+
+// Message return the localized message of the error
+// lang can be a language tag or Accept-Language header, the best available language is used
+// message set by SetMessage is looked up first as message key, then the identifier of codes
+func (c *Catalog) Message(err error, lang string) (string, bool) {
+	var (
+		keys   []string
+		fields Fields
+		errs   *Errs
+	)
+	if As(err, &errs) {
+		if msg := errs.userMessage(); msg != "" {
+			keys = append(keys, msg)
+		}
+		fields = errs.GetRedactedFields()
+	}
+	code := getCode(err)
+	if code == nil {
+		code = Other
+	}
+	keys = append(keys, codeName(code))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range append(ParseAcceptLanguage(lang), c.fallback) {
+		messages := c.lookupLang(l)
+		for _, key := range keys {
+			if message, ok := messages[key]; ok {
+				return fillPlaceholders(message, fields), true
+			}
+		}
+	}
+	return "", false
+}
+
+// lookupLang return messages of the language, base language is used if the region is not available
+func (c *Catalog) lookupLang(lang string) map[string]string {
+	if messages, ok := c.messages[lang]; ok {
+		return messages
+	}
+	if i := strings.Index(lang, "-"); i > 0 {
+		return c.messages[lang[:i]]
+	}
+	return nil
+}
+
+// UserMessage return the localized user message of the error from DefaultCatalog
+// if the message is not available in the catalog, the message set by SetMessage is returned
+// and if it is also not available, the error string of codes is returned
+func UserMessage(err error, lang string) string {
+	if message, ok := DefaultCatalog.Message(err, lang); ok {
+		return message
+	}
+	var errs *Errs
+	if As(err, &errs) {
+		if msg := errs.userMessage(); msg != "" {
+			return msg
+		}
+	}
+	code := getCode(err)
+	if code == nil {
+		code = Other
+	}
+	errString, _ := code.ErrorAndCode()
+	return errString
+}
+
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// fillPlaceholders replace {key} with the value of fields, unknown placeholder is kept
+func fillPlaceholders(message string, fields Fields) string {
+	if len(fields) == 0 {
+		return message
+	}
+	return placeholderPattern.ReplaceAllStringFunc(message, func(placeholder string) string {
+		value, ok := fields[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		return toString(value)
+	})
+}
+
+func toString(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return value.(string)
+	case int:
+		return strconv.Itoa(value.(int))
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		return strings.Trim(string(b), `"`)
+	}
+}
+
+// ParseAcceptLanguage return languages of Accept-Language header ordered by quality
+// a single language tag is also accepted
+func ParseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var langs []language
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		quality := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			params := part[i+1:]
+			part = strings.TrimSpace(part[:i])
+			if q := strings.TrimSpace(params); strings.HasPrefix(q, "q=") {
+				if v, err := strconv.ParseFloat(q[2:], 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if part == "*" || quality <= 0 {
+			continue
+		}
+		langs = append(langs, language{tag: normalizeLang(part), quality: quality})
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].quality > langs[j].quality
+	})
+	tags := make([]string, len(langs))
+	for i := range langs {
+		tags[i] = langs[i].tag
+	}
+	return tags
+}
+
+// normalizeLang convert language tag to lowercase language and uppercase region, like id-ID
+func normalizeLang(lang string) string {
+	lang = strings.Replace(strings.TrimSpace(lang), "_", "-", -1)
+	if i := strings.Index(lang, "-"); i > 0 {
+		return strings.ToLower(lang[:i]) + "-" + strings.ToUpper(lang[i+1:])
+	}
+	return strings.ToLower(lang)
+}
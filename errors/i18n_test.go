@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCatalogMessage(t *testing.T) {
+	catalog := NewCatalog("en")
+	if err := catalog.LoadFiles("testdata/i18n/*.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	locked := New(Conflict, Fields{"order_id": 10})
+	locked.SetMessage("order_locked")
+
+	cases := []struct {
+		err    error
+		lang   string
+		expect string
+		ok     bool
+	}{
+		{New(NotFound, Fields{"order_id": 10}), "id", "Pesanan 10 tidak ditemukan", true},
+		{New(NotFound, Fields{"order_id": "A-1"}), "id-ID", "Pesanan A-1 tidak ditemukan", true},
+		{New(NotFound, Fields{"order_id": 10}), "fr-CH, fr;q=0.9, id;q=0.8, en;q=0.7", "Pesanan 10 tidak ditemukan", true},
+		{New(NotFound, Fields{"order_id": 10}), "fr", "Order 10 is not found", true},
+		{New(NotFound), "en", "Order {order_id} is not found", true},
+		{locked, "id", "Pesanan 10 sedang diproses", true},
+		{New(Op("order.Pay"), locked), "en-US", "Order 10 is being processed", true},
+		// id doesn't have Other, fallback to en
+		{New("something"), "id", "Something went wrong, please try again", true},
+		{New(InvalidArgument), "id", "", false},
+	}
+	for _, c := range cases {
+		message, ok := catalog.Message(c.err, c.lang)
+		if message != c.expect || ok != c.ok {
+			t.Errorf("Expect %q %v for %v in %q but got %q %v", c.expect, c.ok, c.err, c.lang, message, ok)
+		}
+	}
+}
+
+func TestCatalogRedactPlaceholder(t *testing.T) {
+	catalog := NewCatalog("en")
+	catalog.Add("en", map[string]string{"Unauthenticated": "Invalid token {token} for {email}"})
+	err := New(Unauthenticated, Fields{"token": "abc", "email": Sensitive("a@b.c")})
+	message, _ := catalog.Message(err, "en")
+	if expect := "Invalid token [REDACTED] for [REDACTED]"; message != expect {
+		t.Errorf("Expect %q but got %q", expect, message)
+	}
+}
+
+func TestCatalogLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"i18n/en.json":    {Data: []byte(`{"NotFound": "Not here"}`)},
+		"i18n/pt_BR.json": {Data: []byte(`{"NotFound": "Não encontrado"}`)},
+		"i18n/bad.json":   {Data: []byte(`{`)},
+	}
+	catalog := NewCatalog("en")
+	if err := catalog.LoadFS(fsys, "i18n/*.json"); err == nil {
+		t.Error("Expect error for invalid JSON file but got nil")
+	}
+	delete(fsys, "i18n/bad.json")
+	if err := catalog.LoadFS(fsys, "i18n/*.json"); err != nil {
+		t.Fatal(err)
+	}
+	if message, _ := catalog.Message(New(NotFound), "pt-BR"); message != "Não encontrado" {
+		t.Errorf("Expect %q but got %q", "Não encontrado", message)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		expect []string
+	}{
+		{"", []string{}},
+		{"id", []string{"id"}},
+		{"en-us", []string{"en-US"}},
+		{"en;q=0.5, id-ID, *;q=0.1, fr;q=0", []string{"id-ID", "en"}},
+	}
+	for _, c := range cases {
+		if langs := ParseAcceptLanguage(c.header); !reflect.DeepEqual(langs, c.expect) {
+			t.Errorf("Expect %v for %q but got %v", c.expect, c.header, langs)
+		}
+	}
+}
+
+func TestWriteHTTPLang(t *testing.T) {
+	defaultCatalog := DefaultCatalog
+	defer func() { DefaultCatalog = defaultCatalog }()
+	DefaultCatalog = NewCatalog("en")
+	DefaultCatalog.Add("id", map[string]string{"NotFound": "Pesanan {order_id} tidak ditemukan"})
+
+	err := New(NotFound, Fields{"order_id": 10})
+	err.SetMessage("Order is not found")
+
+	cases := []struct {
+		lang   string
+		expect string
+	}{
+		{"id-ID,id;q=0.9", "Pesanan 10 tidak ditemukan"},
+		// not available in the catalog, the raw message is used
+		{"en", "Order is not found"},
+		{"", "Order is not found"},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		if err := WriteHTTPLang(w, err, c.lang); err != nil {
+			t.Fatal(err)
+		}
+		var resp HTTPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Errors[0].UserMessage != c.expect {
+			t.Errorf("Expect %q for %q but got %q", c.expect, c.lang, resp.Errors[0].UserMessage)
+		}
+	}
+
+	// fallback language is used without language
+	DefaultCatalog.Add("en", map[string]string{"Conflict": "Order is changed"})
+	_, resp := ToHTTPResponse(New(Conflict))
+	if resp.Errors[0].UserMessage != "Order is changed" {
+		t.Errorf("Expect %q but got %q", "Order is changed", resp.Errors[0].UserMessage)
+	}
+
+	if message := UserMessage(New(InvalidArgument), "id"); message != "Invalid argument" {
+		t.Errorf("Expect %q but got %q", "Invalid argument", message)
+	}
+}
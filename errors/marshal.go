@@ -0,0 +1,289 @@
+package errors
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// jsonErrs is the JSON representation of Errs
+// the error chain is kept by nesting the previous Errs as cause
+type jsonErrs struct {
+	Op       Op        `json:"op,omitempty"`
+	Code     string    `json:"code,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Err      string    `json:"error,omitempty"`
+	Cause    *jsonErrs `json:"cause,omitempty"`
+	Messages []string  `json:"messages,omitempty"`
+	Fields   Fields    `json:"fields,omitempty"`
+	Traces   []string  `json:"traces,omitempty"`
+	File     string    `json:"file,omitempty"`
+	Line     int       `json:"line,omitempty"`
+	Severity string    `json:"severity,omitempty"`
+}
+
+func toJSONErrs(e *Errs) *jsonErrs {
+	j := &jsonErrs{
+		Op:       e.op,
+		Message:  e.message,
+		Messages: e.messages,
+		Fields:   RedactFields(e.fields),
+		Traces:   e.traces,
+	}
+	if e.severity != SeverityDefault {
+		j.Severity = e.severity.String()
+	}
+	if e.code != nil {
+		j.Code = codeName(e.code)
+	}
+	j.File, j.Line = e.GetFileAndLine()
+	if prev, ok := e.err.(*Errs); ok {
+		j.Cause = toJSONErrs(prev)
+		// the error string of Errs with cause is the detail of the error
+		if e.detail != nil {
+			j.Err = e.detail.Error()
+		}
+	} else if e.err != nil {
+		j.Err = e.err.Error()
+	}
+	return j
+}
+
+func fromJSONErrs(j *jsonErrs) *Errs {
+	e := &Errs{
+		op:       j.Op,
+		message:  j.Message,
+		messages: j.Messages,
+		fields:   j.Fields,
+		traces:   j.Traces,
+		file:     j.File,
+		line:     j.Line,
+		severity: ParseSeverity(j.Severity),
+	}
+	if j.Code != "" {
+		e.code = lookupOrRemoteCodes(j.Code)
+	}
+	if j.Cause != nil {
+		e.err = fromJSONErrs(j.Cause)
+		if j.Err != "" {
+			e.detail = errors.New(j.Err)
+		}
+	} else if j.Err != "" {
+		e.err = errors.New(j.Err)
+	}
+	return e
+}
+
+// lookupOrRemoteCodes return registered codes, or RemoteCodes as internal server error if the codes is not registered
+func lookupOrRemoteCodes(name string) Codes {
+	if code, ok := LookupCodes(name); ok {
+		return code
+	}
+	return RemoteCodes{Name: name, Message: name, HTTPStatus: http.StatusInternalServerError}
+}
+
+// MarshalJSON implement json.Marshaler
+// message, codes identifier, messages, fields, ops, traces and file:line of the whole chain are marshalled
+// fields is redacted by the redact policy
+// the underlying error that is not *Errs is marshalled as string
+func (e *Errs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSONErrs(e))
+}
+
+// UnmarshalJSON implement json.Unmarshaler
+// codes need to be registered by RegisterCodes to be rebuilt, otherwise RemoteCodes is used
+func (e *Errs) UnmarshalJSON(b []byte) error {
+	var j jsonErrs
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*e = *fromJSONErrs(&j)
+	return nil
+}
+
+// binaryVersion is the first byte of binary encoding, to allow the format to be changed later
+// version 2 add severity, version 1 is still decoded
+const binaryVersion byte = 2
+
+var errBadBinary = errors.New("errors: bad binary encoding of Errs")
+
+// MarshalBinary implement encoding.BinaryMarshaler with a compact encoding
+// strings are prefixed by its length, while fields are encoded as JSON
+func (e *Errs) MarshalBinary() ([]byte, error) {
+	b := []byte{binaryVersion}
+	return appendBinary(b, toJSONErrs(e))
+}
+
+// UnmarshalBinary implement encoding.BinaryUnmarshaler
+func (e *Errs) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 || b[0] < 1 || b[0] > binaryVersion {
+		return errBadBinary
+	}
+	d := &binaryDecoder{b: b[1:], version: b[0]}
+	j := d.errs()
+	if d.err != nil {
+		return d.err
+	}
+	*e = *fromJSONErrs(j)
+	return nil
+}
+
+// MarshalError marshal an error into binary, error that is not *Errs is marshalled as its string
+// nil is returned for nil error
+func MarshalError(err error) []byte {
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(*Errs)
+	if !ok {
+		errs = &Errs{err: err}
+	}
+	b, marshalErr := errs.MarshalBinary()
+	if marshalErr != nil {
+		b, _ = (&Errs{err: err}).MarshalBinary()
+	}
+	return b
+}
+
+// UnmarshalError unmarshal binary created by MarshalError
+// nil is returned for empty binary, and an error describing the problem is returned for bad binary
+func UnmarshalError(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	errs := new(Errs)
+	if err := errs.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	return errs
+}
+
+func appendBinary(b []byte, j *jsonErrs) ([]byte, error) {
+	var fields []byte
+	if len(j.Fields) > 0 {
+		var err error
+		fields, err = json.Marshal(j.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b = appendString(b, string(j.Op))
+	b = appendString(b, j.Code)
+	b = appendString(b, j.Message)
+	b = appendString(b, j.Err)
+	b = appendStrings(b, j.Messages)
+	b = appendString(b, string(fields))
+	b = appendStrings(b, j.Traces)
+	b = appendString(b, j.File)
+	b = appendUvarint(b, uint64(j.Line))
+	b = appendString(b, j.Severity)
+	if j.Cause == nil {
+		return append(b, 0), nil
+	}
+	return appendBinary(append(b, 1), j.Cause)
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	return append(b, tmp[:n]...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendStrings(b []byte, ss []string) []byte {
+	b = appendUvarint(b, uint64(len(ss)))
+	for _, s := range ss {
+		b = appendString(b, s)
+	}
+	return b
+}
+
+// binaryDecoder read binary created by appendBinary
+// the first error is kept and the rest of reading is ignored
+type binaryDecoder struct {
+	b       []byte
+	version byte
+	err     error
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errBadBinary
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *binaryDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if uint64(len(d.b)) < n {
+		d.err = errBadBinary
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *binaryDecoder) strings() []string {
+	n := d.uvarint()
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	if uint64(len(d.b)) < n {
+		d.err = errBadBinary
+		return nil
+	}
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = d.string()
+	}
+	return ss
+}
+
+func (d *binaryDecoder) errs() *jsonErrs {
+	j := &jsonErrs{
+		Op:       Op(d.string()),
+		Code:     d.string(),
+		Message:  d.string(),
+		Err:      d.string(),
+		Messages: d.strings(),
+	}
+	if fields := d.string(); fields != "" && d.err == nil {
+		if err := json.Unmarshal([]byte(fields), &j.Fields); err != nil {
+			d.err = err
+		}
+	}
+	j.Traces = d.strings()
+	j.File = d.string()
+	j.Line = int(d.uvarint())
+	if d.version >= 2 {
+		j.Severity = d.string()
+	}
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) == 0 {
+		d.err = errBadBinary
+		return nil
+	}
+	hasCause := d.b[0]
+	d.b = d.b[1:]
+	if hasCause == 1 {
+		j.Cause = d.errs()
+	}
+	return j
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func marshalTestErrs() *Errs {
+	inner := New(Op("repo.Insert"), DatabaseError, errors.New("duplicate key"), Fields{"order_id": 10}, []string{"insert"}, SeverityWarning)
+	inner.SetMessage("Order already exists")
+	inner.file, inner.line = "repo.go", 20
+	err := New(Op("order.Create"), inner, []string{"create"})
+	return Trace(err, "order.Create").(*Errs)
+}
+
+func assertDecoded(t *testing.T, expect, decoded *Errs) {
+	t.Helper()
+	if decoded.Error() != expect.Error() {
+		t.Errorf("Expect %s but got %s", expect.Error(), decoded.Error())
+	}
+	if decoded.GetCode() != expect.GetCode() {
+		t.Errorf("Expect %v but got %v", expect.GetCode(), decoded.GetCode())
+	}
+	if !reflect.DeepEqual(decoded.GetOps(), expect.GetOps()) {
+		t.Errorf("Expect %v but got %v", expect.GetOps(), decoded.GetOps())
+	}
+	if !reflect.DeepEqual(decoded.GetMessages(), expect.GetMessages()) {
+		t.Errorf("Expect %v but got %v", expect.GetMessages(), decoded.GetMessages())
+	}
+	if !reflect.DeepEqual(decoded.GetFields(), Fields{"order_id": float64(10)}) {
+		t.Errorf("Expect fields but got %v", decoded.GetFields())
+	}
+	if !reflect.DeepEqual(decoded.GetTrace(), expect.GetTrace()) {
+		t.Errorf("Expect %v but got %v", expect.GetTrace(), decoded.GetTrace())
+	}
+	if decoded.GetSeverity() != SeverityWarning {
+		t.Errorf("Expect severity warning but got %v", decoded.GetSeverity())
+	}
+	inner := Unwrap(decoded).(*Errs)
+	if file, line := inner.GetFileAndLine(); file != "repo.go" || line != 20 {
+		t.Errorf("Expect repo.go:20 but got %s:%d", file, line)
+	}
+	if inner.GetMessage() != "Order already exists" {
+		t.Errorf("Expect message to be decoded but got %s", inner.GetMessage())
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	err := marshalTestErrs()
+	b, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("Expect no error but got %v", marshalErr)
+	}
+
+	decoded := new(Errs)
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatalf("Expect no error but got %v", err)
+	}
+	assertDecoded(t, err, decoded)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	err := marshalTestErrs()
+	b := MarshalError(err)
+	decoded, ok := UnmarshalError(b).(*Errs)
+	if !ok {
+		t.Fatalf("Expect *Errs but got %v", UnmarshalError(b))
+	}
+	assertDecoded(t, err, decoded)
+
+	if UnmarshalError(MarshalError(nil)) != nil {
+		t.Error("Expect nil error to stay nil")
+	}
+	if err := UnmarshalError(MarshalError(errors.New("plain error"))); err.Error() != "plain error" {
+		t.Errorf("Expect plain error but got %v", err)
+	}
+	for i := 1; i < len(b); i++ {
+		if err := new(Errs).UnmarshalBinary(b[:i]); err == nil {
+			t.Fatalf("Expect error for truncated binary of length %d", i)
+		}
+	}
+}
+
+func TestUnmarshalUnregisteredCodes(t *testing.T) {
+	decoded := new(Errs)
+	if err := json.Unmarshal([]byte(`{"code":"OrderLocked","error":"locked"}`), decoded); err != nil {
+		t.Fatalf("Expect no error but got %v", err)
+	}
+	expect := RemoteCodes{Name: "OrderLocked", Message: "OrderLocked", HTTPStatus: 500}
+	if decoded.GetCode() != expect {
+		t.Errorf("Expect %v but got %v", expect, decoded.GetCode())
+	}
+}
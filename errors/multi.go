@@ -0,0 +1,157 @@
+package errors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MultiErrs collect many errors, for example from validation of batch operations
+// MultiErrs is safe to be used concurrently
+type MultiErrs struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+var _ error = (*MultiErrs)(nil)
+
+// NewMulti create MultiErrs from errors, nil errors are ignored
+func NewMulti(errs ...error) *MultiErrs {
+	m := &MultiErrs{}
+	m.Add(errs...)
+	return m
+}
+
+// Add errors to MultiErrs, nil errors are ignored
+// errors created by errors.Join or other MultiErrs are flattened
+func (m *MultiErrs) Add(errs ...error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, err := range errs {
+		m.errs = appendFlatten(m.errs, err)
+	}
+}
+
+func appendFlatten(errs []error, err error) []error {
+	if err == nil {
+		return errs
+	}
+	multi, ok := multiErrors(err)
+	if !ok {
+		return append(errs, err)
+	}
+	for _, e := range multi {
+		errs = appendFlatten(errs, e)
+	}
+	return errs
+}
+
+// multiErrors return the list of errors if err is an aggregate of errors
+func multiErrors(err error) ([]error, bool) {
+	switch err.(type) {
+	case *Errs:
+		return nil, false
+	case *MultiErrs:
+		return err.(*MultiErrs).Errors(), true
+	case interface{ Unwrap() []error }:
+		return err.(interface{ Unwrap() []error }).Unwrap(), true
+	default:
+		return nil, false
+	}
+}
+
+// Len return the number of errors
+func (m *MultiErrs) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.errs)
+}
+
+// Errors return copy of the errors
+func (m *MultiErrs) Errors() []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]error(nil), m.errs...)
+}
+
+// Unwrap return the errors, so errors.Is and errors.As can check all errors
+func (m *MultiErrs) Unwrap() []error {
+	return m.Errors()
+}
+
+// ErrorOrNil return nil if there is no error, useful to return MultiErrs as error
+func (m *MultiErrs) ErrorOrNil() error {
+	if m == nil || m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error print all errors as a list
+func (m *MultiErrs) Error() string {
+	errs := m.Errors()
+	switch len(errs) {
+	case 0:
+		return "no error"
+	case 1:
+		return errs[0].Error()
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strconv.Itoa(len(errs)) + " errors: " + strings.Join(msgs, "; ")
+}
+
+// Codes return the codes of errors, codes is de-duplicated
+// error without codes is not included
+func (m *MultiErrs) Codes() []Codes {
+	var codes []Codes
+	for _, err := range m.Errors() {
+		code := getCode(err)
+		if code == nil || containsCode(codes, code) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func containsCode(codes []Codes, code Codes) bool {
+	for _, c := range codes {
+		if sameCode(c, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// HTTPStatus decide the overall http status of the errors
+// if all errors have the same status, then the status is used
+// if all errors are client errors, then bad request is used, otherwise internal server error is used
+func (m *MultiErrs) HTTPStatus() int {
+	errs := m.Errors()
+	if len(errs) == 0 {
+		return http.StatusInternalServerError
+	}
+	status := HTTPStatus(errs[0])
+	allClientErrors := true
+	for _, err := range errs {
+		s := HTTPStatus(err)
+		if s != status {
+			status = 0
+		}
+		if s < http.StatusBadRequest || s >= http.StatusInternalServerError {
+			allClientErrors = false
+		}
+	}
+	switch {
+	case status != 0:
+		return status
+	case allClientErrors:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
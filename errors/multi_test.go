@@ -0,0 +1,79 @@
+package errors
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMultiErrs(t *testing.T) {
+	m := NewMulti(nil)
+	if m.ErrorOrNil() != nil {
+		t.Errorf("Expect nil but got %v", m.ErrorOrNil())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Add(New(InvalidArgument, "name is empty"))
+		}()
+	}
+	wg.Wait()
+	m.Add(errors.Join(New(NotFound, sql.ErrNoRows), nil))
+
+	if m.Len() != 4 {
+		t.Fatalf("Expect %d but got %d", 4, m.Len())
+	}
+	if !errors.Is(m, sql.ErrNoRows) {
+		t.Errorf("Expect %v to be found", sql.ErrNoRows)
+	}
+	if !reflect.DeepEqual(m.Codes(), []Codes{InvalidArgument, NotFound}) {
+		t.Errorf("Expect codes to be de-duplicated but got %v", m.Codes())
+	}
+	expect := "4 errors: Invalid argument: name is empty; Invalid argument: name is empty; Invalid argument: name is empty; Not found: sql: no rows in result set"
+	if m.Error() != expect {
+		t.Errorf("Expect %s but got %s", expect, m.Error())
+	}
+}
+
+func TestMultiErrsHTTPStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{err: NewMulti(New(InvalidArgument), New(InvalidArgument)), status: http.StatusBadRequest},
+		{err: NewMulti(New(NotFound), New(NotFound)), status: http.StatusNotFound},
+		{err: NewMulti(New(InvalidArgument), New(NotFound)), status: http.StatusBadRequest},
+		{err: NewMulti(New(InvalidArgument), New(DatabaseError)), status: http.StatusInternalServerError},
+		{err: NewMulti(New(InvalidArgument), errors.New("unknown")), status: http.StatusInternalServerError},
+		{err: errors.Join(New(Unauthenticated), New(Unauthenticated)), status: http.StatusUnauthorized},
+		{err: NewMulti(), status: http.StatusInternalServerError},
+	}
+	for _, val := range cases {
+		if status := HTTPStatus(val.err); status != val.status {
+			t.Errorf("%v: Expect %d but got %d", val.err, val.status, status)
+		}
+	}
+}
+
+func TestMultiErrsWriteHTTP(t *testing.T) {
+	m := NewMulti(
+		New(InvalidArgument, Fields{"row": 1}),
+		New(InvalidArgument, Fields{"row": 2}),
+	)
+	w := httptest.NewRecorder()
+	WriteHTTP(w, m, "row")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expect %d but got %d", http.StatusBadRequest, w.Code)
+	}
+	expect := `{"errors":[{"code":"InvalidArgument","message":"Invalid argument","fields":{"row":1}},{"code":"InvalidArgument","message":"Invalid argument","fields":{"row":2}}]}`
+	if w.Body.String() != expect {
+		t.Errorf("Expect %s but got %s", expect, w.Body.String())
+	}
+}
@@ -0,0 +1,199 @@
+// Package newcheck provide an analyzer to vet the arguments of errors.New and errors.NewCtx
+//
+// errors.New accept ...interface{}, so mistakes like passing map[string]string instead of errors.Fields
+// are only found at runtime as a log line. This analyzer report:
+//   - argument with type which is not supported by errors.New
+//   - duplicate codes argument, as only the last codes is used
+//   - key of Fields literal which is overridden by the same key in a later Fields literal
+//   - dropped *Errs return value
+package newcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check arguments of errors.New
+
+errors.New accept ...interface{}, so unsupported arguments are only found at runtime.
+Report unsupported argument types, duplicate codes, Fields keys which is overridden
+by later Fields arguments and dropped *Errs return values.`
+
+// Analyzer vet the arguments of errors.New
+var Analyzer = &analysis.Analyzer{
+	Name:     "errchecknew",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// errorsPkg is the import path of errors package, can be changed for a fork of the package
+var errorsPkg string
+
+func init() {
+	Analyzer.Flags.StringVar(&errorsPkg, "pkg", "github.com/albert-widi/go_common/errors", "import path of errors package")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.ExprStmt)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		switch n.(type) {
+		case *ast.ExprStmt:
+			checkDropped(pass, n.(*ast.ExprStmt))
+		case *ast.CallExpr:
+			checkNewArgs(pass, n.(*ast.CallExpr))
+		}
+	})
+	return nil, nil
+}
+
+// checkDropped report call statement which drop *Errs
+func checkDropped(pass *analysis.Pass, stmt *ast.ExprStmt) {
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok || !isErrs(pass.TypesInfo.TypeOf(call)) {
+		return
+	}
+	name := "call"
+	if fn := calledFunc(pass.TypesInfo, call); fn != nil {
+		name = fn.Pkg().Name() + "." + fn.Name()
+	}
+	pass.Reportf(call.Pos(), "result of %s is not used", name)
+}
+
+// checkNewArgs report arguments of errors.New that are not supported or ignored
+// the order of the check is the same with the type switch in errors.New
+func checkNewArgs(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := calledFunc(pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != errorsPkg {
+		return
+	}
+	args := call.Args
+	switch fn.Name() {
+	case "New":
+	// the first argument of NewCtx is context
+	case "NewCtx":
+		if len(args) == 0 {
+			return
+		}
+		args = args[1:]
+	default:
+		return
+	}
+	// arguments cannot be checked if a slice is passed as variadic arguments
+	if call.Ellipsis.IsValid() {
+		return
+	}
+
+	var (
+		scope      = fn.Pkg().Scope()
+		errorIface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+		codesIface = lookupType(scope, "Codes")
+		opType     = lookupType(scope, "Op")
+		sevType    = lookupType(scope, "Severity")
+		fieldsType = lookupType(scope, "Fields")
+		stringsTyp = types.NewSlice(types.Typ[types.String])
+		codesArg   ast.Expr
+		fieldKeys  = make(map[string]ast.Expr)
+	)
+	for _, arg := range args {
+		t := pass.TypesInfo.TypeOf(arg)
+		if t == nil {
+			continue
+		}
+		switch {
+		case isString(t):
+		case isErrs(t):
+		case types.Implements(t, errorIface):
+		case codesIface != nil && types.Implements(t, codesIface.Underlying().(*types.Interface)):
+			if codesArg != nil {
+				pass.Reportf(arg.Pos(), "duplicate codes argument, only the last codes is used")
+			}
+			codesArg = arg
+		case opType != nil && types.Identical(t, opType):
+		case sevType != nil && types.Identical(t, sevType):
+		case fieldsType != nil && types.Identical(t, fieldsType):
+			checkFieldKeys(pass, arg, fieldKeys)
+		case types.Identical(t, stringsTyp):
+		case types.IsInterface(t):
+			// dynamic type of interface cannot be checked
+		default:
+			pass.Reportf(arg.Pos(), "unsupported argument type %s for %s.%s", t, fn.Pkg().Name(), fn.Name())
+		}
+	}
+}
+
+// checkFieldKeys report constant keys of Fields literal which override the same key of an earlier Fields literal
+// fields of *Errs argument is merged without overriding, so only Fields arguments are checked
+func checkFieldKeys(pass *analysis.Pass, arg ast.Expr, seen map[string]ast.Expr) {
+	lit, ok := ast.Unparen(arg).(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		value := pass.TypesInfo.Types[kv.Key].Value
+		if value == nil || value.Kind() != constant.String {
+			continue
+		}
+		key := constant.StringVal(value)
+		if earlier, ok := seen[key]; ok {
+			pass.Reportf(earlier.Pos(), "field %q is overridden by the later Fields argument", key)
+		}
+		seen[key] = kv.Key
+	}
+}
+
+// calledFunc return the function called by call, nil if it is not a function or method
+func calledFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+func lookupType(scope *types.Scope, name string) types.Type {
+	obj, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return obj.Type()
+}
+
+// isString report whether t is string, named string type like Op is not supported by errors.New as string
+func isString(t types.Type) bool {
+	basic, ok := t.(*types.Basic)
+	return ok && (basic.Kind() == types.String || basic.Kind() == types.UntypedString)
+}
+
+// isErrs report whether t is *Errs of errors package
+func isErrs(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == errorsPkg && obj.Name() == "Errs"
+}
@@ -0,0 +1,12 @@
+package newcheck_test
+
+import (
+	"testing"
+
+	"github.com/albert-widi/go_common/errors/newcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), newcheck.Analyzer, "a")
+}
@@ -0,0 +1,45 @@
+package a
+
+import (
+	"context"
+	stderr "errors"
+
+	"github.com/albert-widi/go_common/errors"
+)
+
+type myString string
+
+const key = "a"
+
+func valid(err error, code errors.Codes, v interface{}, args []interface{}) error {
+	errors.New("message", errors.Op("a.valid"), errors.NotFound, errors.Fields{"a": 1}, []string{"msg"}) // want "result of errors.New is not used"
+	_ = errors.New(err, code, v)
+	_ = errors.New(stderr.New("std"), errors.New("errs"))
+	_ = errors.New(args...)
+	_ = errors.New(errors.NotFound, errors.SeverityInfo)
+	_ = errors.New(errors.Fields{"a": 1}, errors.Fields{"b": 2})
+	_ = errors.New(errors.Fields{"a": 1}, err, errors.Fields{"b": 2})
+	_ = errors.New(err, errors.Fields{"a": 1})
+	return errors.New(errors.Fields{"a": 1}, "message")
+}
+
+func invalid() {
+	_ = errors.New("message", map[string]string{"a": "b"})                  // want "unsupported argument type map\\[string\\]string for errors.New"
+	_ = errors.New(myString("message"))                                     // want "unsupported argument type a.myString"
+	_ = errors.New(10)                                                      // want "unsupported argument type int"
+	_ = errors.New(nil)                                                     // want "unsupported argument type untyped nil"
+	_ = errors.New(errors.NotFound, errors.DatabaseError)                   // want "duplicate codes argument"
+	_ = errors.New(errors.Fields{"a": 1, "b": 1}, errors.Fields{"a": 2})    // want `field "a" is overridden by the later Fields argument`
+	_ = errors.New(errors.Fields{key: 1}, "message", errors.Fields{"a": 2}) // want `field "a" is overridden by the later Fields argument`
+}
+
+func withContext(ctx context.Context) {
+	_ = errors.NewCtx(ctx, "message", errors.NotFound)
+	_ = errors.NewCtx(ctx, map[string]interface{}{"a": 1}) // want "unsupported argument type map\\[string\\]interface\\{\\} for errors.NewCtx"
+	errors.NewCtx(ctx, "message")                          // want "result of errors.NewCtx is not used"
+}
+
+func withUserMessage(err *errors.Errs) *errors.Errs {
+	err.WithUserMessage("message") // want "result of errors.WithUserMessage is not used"
+	return err.WithUserMessage("message")
+}
@@ -0,0 +1,37 @@
+package errors
+
+import "context"
+
+type Fields map[string]interface{}
+
+type Op string
+
+type Severity int
+
+const SeverityInfo Severity = 2
+
+type Errs struct{}
+
+func (e *Errs) Error() string { return "" }
+
+func (e *Errs) WithUserMessage(message string) *Errs { return e }
+
+type Codes interface {
+	ErrorAndCode() (string, int)
+	Err() error
+}
+
+type DefaultCodes int
+
+const (
+	Other DefaultCodes = iota
+	DatabaseError
+	NotFound
+)
+
+func (c DefaultCodes) ErrorAndCode() (string, int) { return "", 0 }
+func (c DefaultCodes) Err() error                  { return nil }
+
+func New(args ...interface{}) *Errs { return &Errs{} }
+
+func NewCtx(ctx context.Context, args ...interface{}) *Errs { return &Errs{} }
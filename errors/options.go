@@ -0,0 +1,120 @@
+package errors
+
+import "errors"
+
+// Option set a part of Errs, used by NewWith
+type Option func(*Errs)
+
+// NewWith create Errs from options
+// unlike New, the compiler will reject unsupported arguments
+//
+//	err := errors.NewWith(errors.WithOp("order.Get"), errors.WithCode(errors.NotFound), errors.Wrap(sql.ErrNoRows))
+func NewWith(opts ...Option) *Errs {
+	return newErrs(1, false, opts)
+}
+
+// WithString set the underlying error from string, the same as passing string to New
+// if the cause is already *Errs, the string become the detail of this error and the cause is kept
+func WithString(s string) Option {
+	return func(e *Errs) {
+		e.setErr(errors.New(s))
+	}
+}
+
+// Wrap set the cause of the error
+// if cause is *Errs, fields and messages are carried over so they are still visible from the top of the chain
+// fields already set on the error is kept, and messages of the cause is placed first
+// *Errs cause is never replaced, a string or error passed before or after it become the detail of this error
+func Wrap(cause error) Option {
+	return func(e *Errs) {
+		if cause == nil {
+			return
+		}
+		prev, ok := cause.(*Errs)
+		if !ok {
+			e.setErr(cause)
+			return
+		}
+		if _, isErrs := e.err.(*Errs); isErrs {
+			e.detail = cause
+			return
+		}
+		e.fields = e.fields.Merge(prev.fields, MergeKeepFirst)
+		e.messages = appendMessages(prev.messages, e.messages)
+		if e.err != nil {
+			e.detail = e.err
+		}
+		e.err = cause
+	}
+}
+
+// setErr set the underlying error, or the detail if the underlying error is *Errs which must be kept
+func (e *Errs) setErr(err error) {
+	if _, ok := e.err.(*Errs); ok {
+		e.detail = err
+		return
+	}
+	e.err = err
+}
+
+// WithCode set the codes of the error
+func WithCode(code Codes) Option {
+	return func(e *Errs) {
+		e.code = code
+	}
+}
+
+// WithOp set the op of the error
+func WithOp(op Op) Option {
+	return func(e *Errs) {
+		e.op = op
+	}
+}
+
+// WithSeverity set the severity of the error, it override the severity inferred from the codes
+func WithSeverity(severity Severity) Option {
+	return func(e *Errs) {
+		e.severity = severity
+	}
+}
+
+// WithFields replace the fields of the error, fields is copied so changing it later doesn't change the error
+func WithFields(fields Fields) Option {
+	return func(e *Errs) {
+		e.fields = fields.Copy()
+	}
+}
+
+// MergeFields merge fields into the fields of the error, mode decide which value is kept on the same key
+func MergeFields(fields Fields, mode MergeMode) Option {
+	return func(e *Errs) {
+		e.fields = e.fields.Merge(fields, mode)
+	}
+}
+
+// WithField add a field to the error, fields of the error is copied before the field is added
+func WithField(key string, value interface{}) Option {
+	return MergeFields(Fields{key: value}, MergeOverride)
+}
+
+// WithMessages append messages to the error
+func WithMessages(msgs ...string) Option {
+	return func(e *Errs) {
+		e.messages = appendMessages(e.messages, msgs)
+	}
+}
+
+// WithCallerSkip skip n more frames when the caller is recorded
+// it is used by helpers which create the error for its caller, so the caller of the helper is recorded
+func WithCallerSkip(n int) Option {
+	return func(e *Errs) {
+		e.callerSkip += n
+	}
+}
+
+// WithUserMessage set the message of the error, the same as SetMessage
+func WithUserMessage(message string) Option {
+	return func(e *Errs) {
+		e.message = message
+	}
+}
@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"database/sql"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewWith(t *testing.T) {
+	inner := NewWith(
+		WithOp("repo.Get"),
+		WithCode(DatabaseError),
+		Wrap(sql.ErrNoRows),
+		WithField("order_id", 10),
+		WithMessages("select order"),
+	)
+	err := NewWith(
+		WithOp("order.Get"),
+		Wrap(inner),
+		WithField("user_id", 1),
+		WithMessages("get order"),
+		WithUserMessage("Order is not found"),
+	)
+
+	expect := "order.Get: repo.Get: Database error: sql: no rows in result set"
+	if err.Error() != expect {
+		t.Errorf("Expect %s but got %s", expect, err.Error())
+	}
+	if !reflect.DeepEqual(err.GetFields(), Fields{"order_id": 10, "user_id": 1}) {
+		t.Errorf("Expect fields to be merged but got %v", err.GetFields())
+	}
+	if !reflect.DeepEqual(inner.GetFields(), Fields{"order_id": 10}) {
+		t.Errorf("Expect fields of inner error to not be changed but got %v", inner.GetFields())
+	}
+	if !reflect.DeepEqual(err.GetMessages(), []string{"select order", "get order"}) {
+		t.Errorf("Expect messages to be appended but got %v", err.GetMessages())
+	}
+	if err.GetMessage() != "Order is not found" {
+		t.Errorf("Expect message to be set but got %s", err.GetMessage())
+	}
+	if !Is(err, sql.ErrNoRows) {
+		t.Errorf("Expect %v to be found in chain", sql.ErrNoRows)
+	}
+}
+
+func TestNewShim(t *testing.T) {
+	cases := []struct {
+		err    *Errs
+		expect *Errs
+	}{
+		{
+			err:    New(Op("order.Get"), NotFound, "order is not found", Fields{"order_id": 10}, []string{"get order"}),
+			expect: NewWith(WithOp("order.Get"), WithCode(NotFound), WithString("order is not found"), WithFields(Fields{"order_id": 10}), WithMessages("get order")),
+		},
+		{
+			err:    New(sql.ErrNoRows, "replaced"),
+			expect: NewWith(Wrap(sql.ErrNoRows), WithString("replaced")),
+		},
+	}
+	for _, val := range cases {
+		if !reflect.DeepEqual(val.err, val.expect) {
+			t.Errorf("Expect %+v but got %+v", val.expect, val.err)
+		}
+	}
+}
+
+func TestOptionsRuntime(t *testing.T) {
+	SetRuntimeOutput(true)
+	defer SetRuntimeOutput(false)
+
+	errs := []*Errs{New("new"), NewWith(WithString("new with")), WithCodes(NotFound)}
+	for _, err := range errs {
+		if file, _ := err.GetFileAndLine(); !strings.HasSuffix(file, "options_test.go") {
+			t.Errorf("Expect caller to be recorded but got %s", file)
+		}
+	}
+	if file, line := New(map[string]string{}).GetFileAndLine(); line != 0 {
+		t.Errorf("Expect bad call to not record runtime but got %s:%d", file, line)
+	}
+}
+
+func newForCaller() *Errs {
+	return NewWith(WithCode(NotFound), WithCapture(CaptureAlways), WithCallerSkip(1))
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	err := newForCaller()
+	if _, errLine := err.GetFileAndLine(); errLine != line+1 {
+		t.Errorf("Expect caller of the helper at line %d to be recorded but got %d", line+1, errLine)
+	}
+}
@@ -0,0 +1,84 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Recover convert a recovered panic into *Errs and set it to err
+// Recover need to be called directly by defer, otherwise the panic cannot be recovered
+//
+//	func process() (err error) {
+//		defer errors.Recover(&err)
+//		...
+//	}
+//
+// The error have code Other, the panic value and the full goroutine stack in fields
+// If err is nil, the panic is continued as there is nowhere to put the error
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if err == nil {
+		panic(r)
+	}
+	*err = fromPanic(r)
+}
+
+// RecoverFunc convert a recovered panic into *Errs and pass it to fn
+// RecoverFunc need to be called directly by defer, this is useful for http handlers
+//
+//	defer errors.RecoverFunc(func(err error) {
+//		errors.WriteHTTP(w, err)
+//	})
+func RecoverFunc(fn func(err error)) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	fn(fromPanic(r))
+}
+
+func fromPanic(r interface{}) *Errs {
+	// keep the panic error as cause, so it can be checked with Is and As
+	var cause error
+	if err, ok := r.(error); ok {
+		cause = fmt.Errorf("panic: %w", err)
+	} else {
+		cause = fmt.Errorf("panic: %v", r)
+	}
+	return newErrs(panicSkip(), false, []Option{
+		WithCode(Other),
+		Wrap(cause),
+		WithFields(Fields{
+			"panic": fmt.Sprint(r),
+			"stack": string(debug.Stack()),
+		}),
+	})
+}
+
+// panicSkip return the skip of newErrs called by fromPanic, so the frame which panicked is recorded
+// instead of the runtime frames of panic, which is the same for every panic
+func panicSkip() int {
+	pcs := make([]uintptr, 32)
+	// pcs[0] is fromPanic, the same as skip 0 of newErrs
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	inRuntime := false
+	for i := 0; ; i++ {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			inRuntime = true
+		} else if inRuntime {
+			return i
+		}
+		if !more {
+			break
+		}
+	}
+	// the caller of Recover if the panic frames cannot be found
+	return 2
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicWith(v interface{}) (err error) {
+	defer Recover(&err)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	err := panicWith("boom")
+	errs, ok := err.(*Errs)
+	if !ok {
+		t.Fatalf("Expect *Errs but got %v", err)
+	}
+	if errs.GetCode() != Other {
+		t.Errorf("Expect %v but got %v", Other, errs.GetCode())
+	}
+	if errs.Error() != "Internal server error: panic: boom" {
+		t.Errorf("Expect panic message but got %s", errs.Error())
+	}
+	if errs.GetFields()["panic"] != "boom" {
+		t.Errorf("Expect panic value in fields but got %v", errs.GetFields()["panic"])
+	}
+	if stack, _ := errs.GetFields()["stack"].(string); !strings.Contains(stack, "panicWith") {
+		t.Errorf("Expect goroutine stack in fields but got %s", stack)
+	}
+
+	err = panicWith(sql.ErrNoRows)
+	if !Is(err, sql.ErrNoRows) {
+		t.Errorf("Expect %v to be found in chain", sql.ErrNoRows)
+	}
+
+	var noPanic error
+	func() {
+		defer Recover(&noPanic)
+	}()
+	if noPanic != nil {
+		t.Errorf("Expect nil but got %v", noPanic)
+	}
+}
+
+func nilDeref() (err error) {
+	defer Recover(&err)
+	var m *Errs
+	_ = m.code
+	return nil
+}
+
+func TestRecoverSite(t *testing.T) {
+	SetRuntimeOutput(true)
+	defer SetRuntimeOutput(false)
+
+	for _, err := range []error{panicWith("boom"), nilDeref()} {
+		file, line := err.(*Errs).GetFileAndLine()
+		if !strings.HasSuffix(file, "recover_test.go") {
+			t.Errorf("Expect the panicking frame to be recorded but got %s:%d", file, line)
+		}
+	}
+	if Fingerprint(panicWith("boom")) == Fingerprint(nilDeref()) {
+		t.Error("Expect panics from different sites to have different fingerprint")
+	}
+}
+
+func TestRecoverFunc(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		defer RecoverFunc(func(err error) {
+			WriteHTTP(w, err)
+		})
+		panic("boom")
+	}
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expect %d but got %d", http.StatusInternalServerError, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("Expect panic to be hidden but got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted is the replacement of redacted value
+const Redacted = "[REDACTED]"
+
+// SensitiveValue wrap a value which should never be rendered
+// the value is always redacted when fields is rendered, regardless of RedactPolicy
+type SensitiveValue struct {
+	value interface{}
+}
+
+// Sensitive mark a value of fields as sensitive
+//
+//	errors.New("invalid card", errors.Fields{"card_number": errors.Sensitive(card)})
+func Sensitive(v interface{}) SensitiveValue {
+	return SensitiveValue{value: v}
+}
+
+// Value return the original value
+func (s SensitiveValue) Value() interface{} {
+	return s.value
+}
+
+// String implement fmt.Stringer, so the value is not printed by fmt
+func (s SensitiveValue) String() string {
+	return Redacted
+}
+
+// MarshalJSON implement json.Marshaler, so the value is not marshalled
+func (s SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// RedactPolicy decide which fields are redacted when fields is rendered
+// fields is rendered by logger, JSON marshalling, http response and %+v
+type RedactPolicy struct {
+	// Keys is the name of fields to be redacted, case insensitive
+	Keys []string
+	// KeyPatterns redact fields which the name is matched
+	KeyPatterns []*regexp.Regexp
+	// ValuePatterns redact the part of string value which is matched, for example email or card number
+	ValuePatterns []*regexp.Regexp
+	// Types redact value which have the type
+	Types []reflect.Type
+}
+
+// DefaultRedactPolicy redact fields which commonly contain credentials
+var DefaultRedactPolicy = RedactPolicy{
+	Keys: []string{"password", "secret", "token", "access_token", "refresh_token", "authorization", "api_key", "cvv"},
+}
+
+var (
+	redactMu     sync.RWMutex
+	redactPolicy = DefaultRedactPolicy
+	redactKeys   = lowerKeys(DefaultRedactPolicy.Keys)
+)
+
+// SetRedactPolicy replace the redact policy, it is safe to be called at runtime
+func SetRedactPolicy(policy RedactPolicy) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	redactPolicy = policy
+	redactKeys = lowerKeys(policy.Keys)
+}
+
+func lowerKeys(keys []string) map[string]bool {
+	m := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		m[strings.ToLower(key)] = true
+	}
+	return m
+}
+
+// RedactFields return a copy of fields with sensitive values redacted
+// maps with string keys and slices are redacted recursively, they are returned as Fields and []interface{}
+func RedactFields(fields Fields) Fields {
+	if fields == nil {
+		return nil
+	}
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	return redactFields(fields)
+}
+
+// GetRedactedFields return a copy of fields with sensitive values redacted
+// this should be used instead of GetFields when fields is rendered
+func (e *Errs) GetRedactedFields() Fields {
+	return RedactFields(e.fields)
+}
+
+func redactFields(fields map[string]interface{}) Fields {
+	redacted := make(Fields, len(fields))
+	for key, value := range fields {
+		if redactKey(key) {
+			redacted[key] = Redacted
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+	return redacted
+}
+
+func redactKey(key string) bool {
+	if redactKeys[strings.ToLower(key)] {
+		return true
+	}
+	for _, pattern := range redactPolicy.KeyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value.(type) {
+	case nil:
+		return nil
+	case SensitiveValue:
+		return Redacted
+	case Fields:
+		return redactFields(value.(Fields))
+	case map[string]interface{}:
+		return redactFields(value.(map[string]interface{}))
+	case []interface{}:
+		return redactSlice(reflect.ValueOf(value))
+	}
+	t := reflect.TypeOf(value)
+	for _, redactType := range redactPolicy.Types {
+		if t == redactType {
+			return Redacted
+		}
+	}
+	s, ok := value.(string)
+	if !ok {
+		return redactReflect(value)
+	}
+	for _, pattern := range redactPolicy.ValuePatterns {
+		s = pattern.ReplaceAllLiteralString(s, Redacted)
+	}
+	return s
+}
+
+// redactReflect redact maps with string keys and slices of other types, like map[string]string
+// the redacted map is Fields and the redacted slice is []interface{}, other values are returned as is
+func redactReflect(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		fields := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+		return redactFields(fields)
+	case reflect.Slice, reflect.Array:
+		// slice of numbers and bool, including []byte, cannot contain sensitive values
+		if kind := v.Type().Elem().Kind(); kind >= reflect.Bool && kind <= reflect.Complex128 {
+			return value
+		}
+		return redactSlice(v)
+	default:
+		return value
+	}
+}
+
+func redactSlice(v reflect.Value) []interface{} {
+	redacted := make([]interface{}, v.Len())
+	for i := range redacted {
+		redacted[i] = redactValue(v.Index(i).Interface())
+	}
+	return redacted
+}
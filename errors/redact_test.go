@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type email string
+
+func TestRedactFields(t *testing.T) {
+	SetRedactPolicy(RedactPolicy{
+		Keys:          []string{"Password"},
+		KeyPatterns:   []*regexp.Regexp{regexp.MustCompile(`_token$`)},
+		ValuePatterns: []*regexp.Regexp{regexp.MustCompile(`\b\d{16}\b`)},
+		Types:         []reflect.Type{reflect.TypeOf(email(""))},
+	})
+	defer SetRedactPolicy(DefaultRedactPolicy)
+
+	fields := Fields{
+		"password":      "secret",
+		"refresh_token": "abc",
+		"note":          "card 4111111111111111 is declined",
+		"email":         email("user@example.com"),
+		"card":          Sensitive("4111111111111111"),
+		"nested":        map[string]interface{}{"PASSWORD": "secret", "order_id": 10},
+		"user":          map[string]string{"password": "hunter2", "name": "user"},
+		"retries":       []interface{}{"abc", Sensitive("4111111111111111")},
+		"notes":         []string{"card 4111111111111111"},
+		"ids":           []int{1, 2},
+		"order_id":      10,
+	}
+	expect := Fields{
+		"password":      Redacted,
+		"refresh_token": Redacted,
+		"note":          "card " + Redacted + " is declined",
+		"email":         Redacted,
+		"card":          Redacted,
+		"nested":        Fields{"PASSWORD": Redacted, "order_id": 10},
+		"user":          Fields{"password": Redacted, "name": "user"},
+		"retries":       []interface{}{"abc", Redacted},
+		"notes":         []interface{}{"card " + Redacted},
+		"ids":           []int{1, 2},
+		"order_id":      10,
+	}
+	redacted := RedactFields(fields)
+	if !reflect.DeepEqual(redacted, expect) {
+		t.Errorf("Expect %v but got %v", expect, redacted)
+	}
+	if fields["password"] != "secret" {
+		t.Error("Expect original fields to not be changed")
+	}
+	if RedactFields(nil) != nil {
+		t.Error("Expect nil fields to stay nil")
+	}
+}
+
+func TestRedactRendering(t *testing.T) {
+	err := New(InvalidArgument, "invalid card", Fields{"token": "abc", "card": Sensitive("4111111111111111"), "order_id": 10})
+	if v := err.GetFields()["card"].(SensitiveValue).Value(); v != "4111111111111111" {
+		t.Errorf("Expect original value but got %v", v)
+	}
+
+	rendered := map[string]string{}
+	b, _ := json.Marshal(err)
+	rendered["json"] = string(b)
+	decoded, _ := UnmarshalError(MarshalError(err)).(*Errs)
+	rendered["binary"] = fmt.Sprint(decoded.GetFields())
+	w := httptest.NewRecorder()
+	WriteHTTP(w, err, "token", "card", "order_id")
+	rendered["http"] = w.Body.String()
+	rendered["format"] = fmt.Sprintf("%+v", err)
+
+	for name, s := range rendered {
+		if strings.Contains(s, "4111111111111111") || strings.Contains(s, "abc") {
+			t.Errorf("%s: Expect sensitive values to be redacted but got %s", name, s)
+		}
+		if !strings.Contains(s, Redacted) {
+			t.Errorf("%s: Expect %s in %s", name, Redacted, s)
+		}
+	}
+	// sensitive value is not printed even when fields is printed directly
+	if s := fmt.Sprint(err.GetFields()); strings.Contains(s, "4111111111111111") {
+		t.Errorf("Expect sensitive value to not be printed but got %s", s)
+	}
+}
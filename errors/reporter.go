@@ -0,0 +1,319 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reporter report errors to external service, for example an error tracker
+type Reporter interface {
+	// Report queue the error to be reported, it should not block
+	Report(err error)
+	// Flush send all queued errors
+	Flush(ctx context.Context) error
+	// Close flush all queued errors and stop the reporter
+	Close(ctx context.Context) error
+}
+
+var (
+	reporterMu      sync.RWMutex
+	defaultReporter Reporter
+)
+
+// SetReporter set the reporter used by Report
+func SetReporter(r Reporter) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	defaultReporter = r
+}
+
+// Report report the error with the reporter set by SetReporter
+// nothing is done if reporter is not set
+func Report(err error) {
+	reporterMu.RLock()
+	r := defaultReporter
+	reporterMu.RUnlock()
+	if r != nil {
+		r.Report(err)
+	}
+}
+
+// ReporterOption for HTTPReporter
+type ReporterOption struct {
+	// Endpoint is the url where the errors is POSTed as JSON
+	Endpoint string
+	// Client used to send the errors, http.DefaultClient is used if nil
+	Client *http.Client
+	// BatchSize is the maximum number of errors in one request, default is 100
+	BatchSize int
+	// FlushInterval is the interval of sending queued errors, default is 5 seconds
+	FlushInterval time.Duration
+	// QueueSize is the maximum number of queued errors, errors is dropped when the queue is full, default is 1000
+	QueueSize int
+	// SampleRate is the rate of reported errors for each fingerprint, 0 is treated as 1
+	// the first error of each fingerprint in a flush interval is always reported
+	// negative rate will only report the first error of each fingerprint in a flush interval
+	SampleRate float64
+	// RateLimit is the maximum number of reported errors per second, 0 is unlimited
+	RateLimit int
+}
+
+// HTTPReporter batch errors and POST them as JSON to the endpoint
+type HTTPReporter struct {
+	opt     ReporterOption
+	queue   chan ReportItem
+	flushCh chan flushRequest
+	closing chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+
+	// closeCtx is the context passed to Close, it is set before closing is closed
+	closeCtx context.Context
+
+	// counts of each fingerprint in the current flush interval, used for sampling
+	mu      sync.Mutex
+	counts  map[string]int
+	rnd     *rand.Rand
+	limiter *rateLimiter
+}
+
+var _ Reporter = (*HTTPReporter)(nil)
+
+// flushRequest ask the worker to send all queued errors with the context of Flush
+type flushRequest struct {
+	ctx context.Context
+	ch  chan error
+}
+
+// ReportItem is the JSON representation of reported error
+type ReportItem struct {
+	Fingerprint string    `json:"fingerprint"`
+	Time        time.Time `json:"time"`
+	Error       *Errs     `json:"error"`
+}
+
+// ReportPayload is the JSON body sent by HTTPReporter
+type ReportPayload struct {
+	Errors []ReportItem `json:"errors"`
+}
+
+// NewHTTPReporter create HTTPReporter and start sending errors in background
+func NewHTTPReporter(opt ReporterOption) *HTTPReporter {
+	if opt.Client == nil {
+		opt.Client = http.DefaultClient
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 100
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = time.Second * 5
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = 1000
+	}
+	if opt.SampleRate == 0 {
+		opt.SampleRate = 1
+	}
+	r := &HTTPReporter{
+		opt:     opt,
+		queue:   make(chan ReportItem, opt.QueueSize),
+		flushCh: make(chan flushRequest),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+		counts:  make(map[string]int),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		limiter: &rateLimiter{limit: opt.RateLimit},
+	}
+	go r.run()
+	return r
+}
+
+// Report queue the error to be reported
+// the error is dropped if it is not sampled, rate limited, the queue is full or the reporter is closed
+func (r *HTTPReporter) Report(err error) {
+	if err == nil {
+		return
+	}
+	select {
+	case <-r.closing:
+		return
+	default:
+	}
+
+	fingerprint := Fingerprint(err)
+	if !r.sample(fingerprint) || !r.limiter.allow(time.Now()) {
+		return
+	}
+	var errs *Errs
+	if !As(err, &errs) {
+		errs = &Errs{err: err}
+	}
+	select {
+	case r.queue <- ReportItem{Fingerprint: fingerprint, Time: time.Now(), Error: errs}:
+	default:
+		atomic.AddUint64(&r.dropped, 1)
+	}
+}
+
+// Dropped return the number of errors dropped because the queue is full
+func (r *HTTPReporter) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
+
+// Flush send all queued errors and wait until it is sent
+// the requests is canceled when ctx is done
+func (r *HTTPReporter) Flush(ctx context.Context) error {
+	ch := make(chan error, 1)
+	select {
+	case r.flushCh <- flushRequest{ctx: ctx, ch: ch}:
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flush all queued errors and stop the reporter, errors reported after Close is ignored
+// the requests is canceled when ctx of the first Close is done
+func (r *HTTPReporter) Close(ctx context.Context) error {
+	r.once.Do(func() {
+		r.closeCtx = ctx
+		close(r.closing)
+	})
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *HTTPReporter) sample(fingerprint string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[fingerprint]++
+	if r.counts[fingerprint] == 1 || r.opt.SampleRate >= 1 {
+		return true
+	}
+	return r.rnd.Float64() < r.opt.SampleRate
+}
+
+func (r *HTTPReporter) resetCounts() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts = make(map[string]int)
+}
+
+func (r *HTTPReporter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opt.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]ReportItem, 0, r.opt.BatchSize)
+	for {
+		select {
+		case item := <-r.queue:
+			batch = append(batch, item)
+			if len(batch) >= r.opt.BatchSize {
+				r.send(context.Background(), batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.send(context.Background(), batch)
+			batch = batch[:0]
+			r.resetCounts()
+		case req := <-r.flushCh:
+			req.ch <- r.sendAll(req.ctx, batch)
+			batch = batch[:0]
+		case <-r.closing:
+			r.sendAll(r.closeCtx, batch)
+			return
+		}
+	}
+}
+
+// sendAll send the batch and all queued errors
+// it stop when ctx is done, the remaining queued errors is sent by the next flush
+func (r *HTTPReporter) sendAll(ctx context.Context, batch []ReportItem) error {
+	var firstErr error
+	for {
+		select {
+		case item := <-r.queue:
+			batch = append(batch, item)
+			if len(batch) < r.opt.BatchSize {
+				continue
+			}
+		default:
+		}
+		if err := r.send(ctx, batch); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if len(r.queue) == 0 || ctx.Err() != nil {
+			return firstErr
+		}
+		batch = batch[:0]
+	}
+}
+
+func (r *HTTPReporter) send(ctx context.Context, batch []ReportItem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(ReportPayload{Errors: batch})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.opt.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.opt.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("errors: reporter endpoint return status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// rateLimiter limit the number of events per second with a fixed window
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Time
+	count  int
+}
+
+func (l *rateLimiter) allow(now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.window) >= time.Second {
+		l.window = now
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		return false
+	}
+	l.count++
+	return true
+}
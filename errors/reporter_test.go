@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type reportServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []ReportPayload
+}
+
+func newReportServer(t *testing.T) *reportServer {
+	s := &reportServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload ReportPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("Expect valid payload but got %v", err)
+		}
+		s.mu.Lock()
+		s.payloads = append(s.payloads, payload)
+		s.mu.Unlock()
+	}))
+	return s
+}
+
+func (s *reportServer) items() []ReportItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []ReportItem
+	for _, payload := range s.payloads {
+		items = append(items, payload.Errors...)
+	}
+	return items
+}
+
+func TestHTTPReporter(t *testing.T) {
+	server := newReportServer(t)
+	defer server.Close()
+
+	r := NewHTTPReporter(ReporterOption{Endpoint: server.URL, BatchSize: 2, FlushInterval: time.Hour})
+	r.Report(New(NotFound, "order 1 is not found", Fields{"order_id": 1, "token": "abc"}))
+	r.Report(New(Op("order.Get"), DatabaseError))
+	r.Report(New("plain error"))
+	r.Report(nil)
+	if err := r.Close(context.Background()); err != nil {
+		t.Fatalf("Expect no error but got %v", err)
+	}
+	r.Report(New("reported after close"))
+
+	items := server.items()
+	if len(items) != 3 {
+		t.Fatalf("Expect %d errors but got %d", 3, len(items))
+	}
+	if len(server.payloads) != 2 {
+		t.Errorf("Expect %d batches but got %d", 2, len(server.payloads))
+	}
+	first := items[0]
+	if first.Error.GetCode() != NotFound || first.Fingerprint == "" {
+		t.Errorf("Expect code and fingerprint to be reported but got %+v", first)
+	}
+	if first.Error.GetFields()["token"] != Redacted {
+		t.Errorf("Expect fields to be redacted but got %v", first.Error.GetFields())
+	}
+}
+
+func TestHTTPReporterSampling(t *testing.T) {
+	server := newReportServer(t)
+	defer server.Close()
+
+	r := NewHTTPReporter(ReporterOption{Endpoint: server.URL, FlushInterval: time.Hour, SampleRate: -1})
+	for i := 0; i < 10; i++ {
+		r.Report(New(NotFound, "order is not found"))
+		r.Report(New(DatabaseError))
+	}
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("Expect no error but got %v", err)
+	}
+	if items := server.items(); len(items) != 2 {
+		t.Errorf("Expect only first error of each fingerprint but got %d", len(items))
+	}
+	r.Close(context.Background())
+}
+
+func TestHTTPReporterLimit(t *testing.T) {
+	server := newReportServer(t)
+	defer server.Close()
+
+	r := NewHTTPReporter(ReporterOption{Endpoint: server.URL, FlushInterval: time.Hour, RateLimit: 3})
+	for i := 0; i < 10; i++ {
+		r.Report(New("error"))
+	}
+	r.Close(context.Background())
+	if items := server.items(); len(items) != 3 {
+		t.Errorf("Expect %d errors but got %d", 3, len(items))
+	}
+}
+
+// blockedServer doesn't respond until it is closed or the request is canceled
+type blockedServer struct {
+	*httptest.Server
+	started  chan struct{}
+	canceled chan struct{}
+	release  chan struct{}
+}
+
+func newBlockedServer() *blockedServer {
+	s := &blockedServer{
+		started:  make(chan struct{}, 10),
+		canceled: make(chan struct{}, 10),
+		release:  make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the body is read, so the server notice when the request is canceled
+		io.Copy(io.Discard, r.Body)
+		s.started <- struct{}{}
+		select {
+		case <-s.release:
+		case <-r.Context().Done():
+			s.canceled <- struct{}{}
+		}
+	}))
+	return s
+}
+
+func (s *blockedServer) Close() {
+	close(s.release)
+	s.Server.Close()
+}
+
+func TestHTTPReporterBlocked(t *testing.T) {
+	server := newBlockedServer()
+
+	// the worker is blocked sending the first error, so the queue is not consumed
+	queued := NewHTTPReporter(ReporterOption{Endpoint: server.URL, BatchSize: 1, FlushInterval: time.Hour, QueueSize: 2})
+	queued.Report(New("error"))
+	<-server.started
+	for i := 0; i < 5; i++ {
+		queued.Report(New("error"))
+	}
+	if queued.Dropped() != 3 {
+		t.Errorf("Expect %d dropped errors but got %d", 3, queued.Dropped())
+	}
+
+	flushed := NewHTTPReporter(ReporterOption{Endpoint: server.URL, FlushInterval: time.Hour})
+	flushed.Report(New("error"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := flushed.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expect %v but got %v", context.DeadlineExceeded, err)
+	}
+	select {
+	case <-server.canceled:
+	case <-time.After(time.Second):
+		t.Error("Expect the request to be canceled with the context of Flush")
+	}
+
+	server.Close()
+	queued.Close(context.Background())
+	flushed.Close(context.Background())
+}
+
+func TestReport(t *testing.T) {
+	server := newReportServer(t)
+	defer server.Close()
+
+	Report(New("no reporter"))
+	r := NewHTTPReporter(ReporterOption{Endpoint: server.URL})
+	SetReporter(r)
+	defer SetReporter(nil)
+	Report(New("with reporter"))
+	r.Close(context.Background())
+	if items := server.items(); len(items) != 1 {
+		t.Errorf("Expect %d errors but got %d", 1, len(items))
+	}
+}
@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+)
+
+// RetryableCodes is an optional interface of Codes
+// implemented by codes which know whether the failed operation is worth to be retried
+type RetryableCodes interface {
+	Codes
+	Retryable() bool
+}
+
+// TemporaryCodes is an optional interface of Codes
+// implemented by codes which know whether the error is caused by a temporary condition
+type TemporaryCodes interface {
+	Codes
+	Temporary() bool
+}
+
+// TimeoutCodes is an optional interface of Codes
+// implemented by codes which know whether the error is caused by timeout
+type TimeoutCodes interface {
+	Codes
+	Timeout() bool
+}
+
+var (
+	_ RetryableCodes = Other
+	_ TemporaryCodes = Other
+	_ TimeoutCodes   = Other
+	_ RetryableCodes = RemoteCodes{}
+	_ TemporaryCodes = RemoteCodes{}
+	_ TimeoutCodes   = RemoteCodes{}
+)
+
+// Temporary report whether the codes is caused by a temporary condition
+// every retryable DefaultCodes is caused by a temporary condition
+func (c DefaultCodes) Temporary() bool {
+	return c.Retryable()
+}
+
+// Timeout report whether the codes is caused by timeout
+func (c DefaultCodes) Timeout() bool {
+	return c == RequestTimeOutError || c == DeadlineExceeded
+}
+
+// Retryable report whether the remote codes is worth to be retried based on its http status
+func (c RemoteCodes) Retryable() bool {
+	switch c.HTTPStatus {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Temporary report whether the remote codes is caused by a temporary condition based on its http status
+func (c RemoteCodes) Temporary() bool {
+	return c.Retryable()
+}
+
+// Timeout report whether the remote codes is caused by timeout based on its http status
+func (c RemoteCodes) Timeout() bool {
+	return c.HTTPStatus == http.StatusRequestTimeout || c.HTTPStatus == http.StatusGatewayTimeout
+}
+
+// IsRetryable report whether the failed operation is worth to be retried
+// the error chain is walked and the first error which can be classified decide the result:
+//   - *Errs with codes implementing RetryableCodes, except catch-all codes like DatabaseError
+//     which let the cause decide
+//   - error implementing Retryable() bool
+//   - timeout and temporary errors, such as context.DeadlineExceeded and net.Error, are retryable
+//   - context.Canceled is not retryable
+func IsRetryable(err error) bool {
+	for ; err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok {
+			if code, ok := errs.code.(RetryableCodes); ok && !isCatchAll(code) {
+				return code.Retryable()
+			}
+			continue
+		}
+		if e, ok := err.(interface{ Retryable() bool }); ok {
+			return e.Retryable()
+		}
+		if v, ok := classify(err); ok {
+			return v
+		}
+	}
+	return false
+}
+
+// IsTemporary report whether the error is caused by a temporary condition
+// timeout is treated as a temporary condition, and catch-all codes let the cause decide like IsRetryable
+func IsTemporary(err error) bool {
+	for ; err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok {
+			if code, ok := errs.code.(TemporaryCodes); ok && !isCatchAll(code) {
+				return code.Temporary()
+			}
+			if code, ok := errs.code.(TimeoutCodes); ok && code.Timeout() {
+				return true
+			}
+			continue
+		}
+		if v, ok := classify(err); ok {
+			return v
+		}
+	}
+	return false
+}
+
+// IsTimeout report whether the error is caused by timeout
+// RequestTimeOutError, DeadlineExceeded, context.DeadlineExceeded and net.Error with Timeout() true are timeout
+func IsTimeout(err error) bool {
+	for ; err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok {
+			if code, ok := errs.code.(TimeoutCodes); ok && code.Timeout() {
+				return true
+			}
+			continue
+		}
+		if err == context.DeadlineExceeded {
+			return true
+		}
+		if e, ok := err.(interface{ Timeout() bool }); ok && e.Timeout() {
+			return true
+		}
+	}
+	return false
+}
+
+// isCatchAll report whether the codes is a generic codes which doesn't describe the cause
+// such codes is commonly wrapped around the original error, so the cause is classified instead
+func isCatchAll(code Codes) bool {
+	switch code {
+	case Other, DatabaseError, RedisError, Internal:
+		return true
+	default:
+		return false
+	}
+}
+
+// classify errors from standard library
+// ok is false if the error cannot be classified
+func classify(err error) (temporary bool, ok bool) {
+	switch err {
+	case context.DeadlineExceeded:
+		return true, true
+	case context.Canceled:
+		return false, true
+	}
+	if e, ok := err.(interface{ Timeout() bool }); ok && e.Timeout() {
+		return true, true
+	}
+	// net.Error Temporary is deprecated, but is still the only signal for some errors
+	if e, ok := err.(interface{ Temporary() bool }); ok {
+		return e.Temporary(), true
+	}
+	return false, false
+}
@@ -0,0 +1,47 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestClassification(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		retryable bool
+		temporary bool
+		timeout   bool
+	}{
+		{name: "nil", err: nil},
+		{name: "not found", err: New(NotFound, sql.ErrNoRows)},
+		{name: "request timeout", err: New(Op("order.Get"), New(RequestTimeOutError)), retryable: true, temporary: true, timeout: true},
+		{name: "unavailable", err: New(Unavailable), retryable: true, temporary: true},
+		{name: "deadline", err: New(Op("order.Get"), context.DeadlineExceeded), retryable: true, temporary: true, timeout: true},
+		{name: "wrapped deadline", err: fmt.Errorf("get order: %w", context.DeadlineExceeded), retryable: true, temporary: true, timeout: true},
+		{name: "canceled", err: New(context.Canceled)},
+		{name: "net timeout", err: New(&net.DNSError{Err: "i/o timeout", IsTimeout: true}), retryable: true, temporary: true, timeout: true},
+		{name: "net temporary", err: New(&net.DNSError{Err: "server misbehaving", IsTemporary: true}), retryable: true, temporary: true},
+		{name: "net not found", err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		{name: "code decides", err: New(InvalidArgument, context.DeadlineExceeded), timeout: true},
+		{name: "catch-all code", err: New(DatabaseError, context.DeadlineExceeded), retryable: true, temporary: true, timeout: true},
+		{name: "catch-all without cause", err: New(Internal, "failed")},
+		{name: "remote unavailable", err: New(RemoteCodes{Name: "502", HTTPStatus: 502}), retryable: true, temporary: true},
+		{name: "remote timeout", err: New(RemoteCodes{Name: "504", HTTPStatus: 504}), retryable: true, temporary: true, timeout: true},
+	}
+
+	for _, val := range cases {
+		if IsRetryable(val.err) != val.retryable {
+			t.Errorf("%s: Expect retryable %v but got %v", val.name, val.retryable, IsRetryable(val.err))
+		}
+		if IsTemporary(val.err) != val.temporary {
+			t.Errorf("%s: Expect temporary %v but got %v", val.name, val.temporary, IsTemporary(val.err))
+		}
+		if IsTimeout(val.err) != val.timeout {
+			t.Errorf("%s: Expect timeout %v but got %v", val.name, val.timeout, IsTimeout(val.err))
+		}
+	}
+}
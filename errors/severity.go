@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Severity is how bad the error is, it is used by logger to choose the log level
+// expected errors like NotFound is not worth an error log line
+type Severity int
+
+const (
+	// SeverityDefault mean the severity is not set and inferred from the codes
+	SeverityDefault Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// String return the lowercase name of severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityDefault:
+		return "default"
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// ParseSeverity return severity from its name, SeverityDefault is returned for unknown name
+func ParseSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "debug":
+		return SeverityDebug
+	case "info":
+		return SeverityInfo
+	case "warn", "warning":
+		return SeverityWarning
+	case "error":
+		return SeverityError
+	default:
+		return SeverityDefault
+	}
+}
+
+// SeverityCodes is an optional interface of Codes
+// implemented by codes which know the severity of the error, otherwise the severity is inferred from http status
+type SeverityCodes interface {
+	Codes
+	Severity() Severity
+}
+
+var _ SeverityCodes = Other
+
+// Severity return the severity of the codes
+// not found and canceled request is expected, other client errors is a warning and the rest is an error
+func (c DefaultCodes) Severity() Severity {
+	_, status := c.ErrorAndCode()
+	return statusSeverity(status)
+}
+
+func statusSeverity(status int) Severity {
+	switch {
+	case status == http.StatusNotFound, status == StatusClientClosedRequest:
+		return SeverityInfo
+	case status >= 400 && status < 500:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// codesSeverity return the severity of codes, SeverityError is returned for nil codes
+func codesSeverity(code Codes) Severity {
+	if code == nil {
+		return SeverityError
+	}
+	if c, ok := code.(SeverityCodes); ok {
+		if s := c.Severity(); s != SeverityDefault {
+			return s
+		}
+	}
+	_, status := code.ErrorAndCode()
+	return statusSeverity(status)
+}
+
+// GetSeverity return the severity of the error
+// the outermost severity set explicitly is used, otherwise it is inferred from the codes returned by GetCode
+func (e *Errs) GetSeverity() Severity {
+	for err := error(e); err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok && errs.severity != SeverityDefault {
+			return errs.severity
+		}
+	}
+	return codesSeverity(e.GetCode())
+}
+
+// GetSeverity return the severity of the error, error that is not *Errs is an error
+// the highest severity is returned for multiple errors
+// SeverityDefault is returned for nil error
+func GetSeverity(err error) Severity {
+	if err == nil {
+		return SeverityDefault
+	}
+	if errs, ok := multiErrors(err); ok {
+		severity := SeverityDefault
+		for _, e := range errs {
+			if s := GetSeverity(e); s > severity {
+				severity = s
+			}
+		}
+		return severity
+	}
+	var errs *Errs
+	if As(err, &errs) {
+		return errs.GetSeverity()
+	}
+	return SeverityError
+}
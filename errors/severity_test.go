@@ -0,0 +1,70 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetSeverity(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect Severity
+	}{
+		{nil, SeverityDefault},
+		{errors.New("plain"), SeverityError},
+		{New("no codes"), SeverityError},
+		{New(NotFound), SeverityInfo},
+		{New(Canceled), SeverityInfo},
+		{New(InvalidArgument), SeverityWarning},
+		{New(DatabaseError), SeverityError},
+		{New(Unavailable), SeverityError},
+		{New(NotFound, SeverityError), SeverityError},
+		// the outermost explicit severity win
+		{New(Op("order.Get"), New(DatabaseError, SeverityDebug)), SeverityDebug},
+		{New(SeverityWarning, New(DatabaseError, SeverityDebug)), SeverityWarning},
+		// the outermost codes is used to infer the severity
+		{New(Internal, New(NotFound)), SeverityError},
+		{NewWith(WithCode(NotFound), WithSeverity(SeverityDebug)), SeverityDebug},
+		{New(RemoteCodes{Name: "OrderLocked", HTTPStatus: 409}), SeverityWarning},
+		// inferred from http status of codes which doesn't implement SeverityCodes
+		{New(testCodes(1)), SeverityWarning},
+		{NewMulti(New(NotFound), New(InvalidArgument)), SeverityWarning},
+		{errors.Join(New(NotFound), errors.New("plain")), SeverityError},
+	}
+	for _, c := range cases {
+		if s := GetSeverity(c.err); s != c.expect {
+			t.Errorf("Expect %v for %v but got %v", c.expect, c.err, s)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for s := SeverityDebug; s <= SeverityError; s++ {
+		if ParseSeverity(s.String()) != s {
+			t.Errorf("Expect %v to be parsed from its string", s)
+		}
+	}
+	if ParseSeverity("WARN") != SeverityWarning {
+		t.Error("Expect WARN to be parsed as warning")
+	}
+	if ParseSeverity("fatal") != SeverityDefault {
+		t.Error("Expect unknown severity to be default")
+	}
+}
+
+func TestUnmarshalBinaryVersion1(t *testing.T) {
+	// op, code, message, error, messages, fields, traces, file, line and no cause
+	b := append([]byte{1, 0, 8}, "NotFound"...)
+	b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+	err := UnmarshalError(b)
+	errs, ok := err.(*Errs)
+	if !ok {
+		t.Fatalf("Expect *Errs but got %v", err)
+	}
+	if errs.GetCode() != NotFound || errs.GetSeverity() != SeverityInfo {
+		t.Errorf("Expect NotFound with info severity but got %v %v", errs.GetCode(), errs.GetSeverity())
+	}
+	if err := UnmarshalError([]byte{3, 0}); err != errBadBinary {
+		t.Errorf("Expect unknown version to be rejected but got %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package sqlerr classify database driver errors into errors codes
+//
+// go-sql-driver/mysql and lib/pq errors are inspected, so DatabaseError is only used
+// when the error cannot be classified more precisely.
+package sqlerr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/albert-widi/go_common/errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Classify wrap database error into *errors.Errs with the classified codes
+// table and constraint of the error are added as fields when the driver provide them
+// nil is returned if err is nil
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	code, fields := classify(err)
+	// the caller of Classify is recorded as where the error is created
+	return errors.NewWith(
+		errors.WithCode(code),
+		errors.Wrap(err),
+		errors.MergeFields(fields, errors.MergeOverride),
+		errors.WithCallerSkip(1),
+	)
+}
+
+// Code return the classified codes of database error
+// DatabaseError is returned if the error cannot be classified
+func Code(err error) errors.Codes {
+	code, _ := classify(err)
+	return code
+}
+
+func classify(err error) (errors.Codes, errors.Fields) {
+	fields := errors.Fields{}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errors.NotFound, fields
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return errors.Unavailable, fields
+	}
+
+	var (
+		mysqlErr *mysql.MySQLError
+		pqErr    *pq.Error
+	)
+	switch {
+	case errors.As(err, &mysqlErr):
+		fields["db_code"] = strconv.Itoa(int(mysqlErr.Number))
+		return classifyMySQL(mysqlErr, fields), fields
+	case errors.As(err, &pqErr):
+		fields["db_code"] = string(pqErr.Code)
+		return classifyPostgres(pqErr, fields), fields
+	}
+	return errors.DatabaseError, fields
+}
+
+var (
+	// Duplicate entry 'value' for key 'orders.idx_order_no', MySQL before 8.0 doesn't prefix the key with the table
+	mysqlDuplicateKey = regexp.MustCompile("for key '([^']+)'")
+	// Cannot add or update a child row: a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk_user` ...
+	mysqlForeignKey = regexp.MustCompile("\\(`[^`]+`\\.`([^`]+)`, CONSTRAINT `([^`]+)`")
+)
+
+// MySQL server error numbers
+// https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlDuplicateEntry         = 1062
+	mysqlDuplicateEntryWithKey  = 1586
+	mysqlNoReferencedRow        = 1216
+	mysqlRowIsReferenced        = 1217
+	mysqlRowIsReferenced2       = 1451
+	mysqlNoReferencedRow2       = 1452
+	mysqlLockWaitTimeout        = 1205
+	mysqlLockDeadlock           = 1213
+	mysqlTooManyConnections     = 1040
+	mysqlBadNull                = 1048
+	mysqlDataTooLong            = 1406
+	mysqlWarnDataOutOfRange     = 1264
+	mysqlTruncatedWrongValue    = 1292
+	mysqlCheckConstraintViolate = 3819
+)
+
+func classifyMySQL(err *mysql.MySQLError, fields errors.Fields) errors.Codes {
+	switch err.Number {
+	case mysqlDuplicateEntry, mysqlDuplicateEntryWithKey:
+		if m := mysqlDuplicateKey.FindStringSubmatch(err.Message); m != nil {
+			if dot := strings.Index(m[1], "."); dot >= 0 {
+				fields["table"] = m[1][:dot]
+				fields["constraint"] = m[1][dot+1:]
+			} else {
+				fields["constraint"] = m[1]
+			}
+		}
+		return errors.AlreadyExists
+	case mysqlNoReferencedRow, mysqlRowIsReferenced, mysqlRowIsReferenced2, mysqlNoReferencedRow2:
+		if m := mysqlForeignKey.FindStringSubmatch(err.Message); m != nil {
+			fields["table"] = m[1]
+			fields["constraint"] = m[2]
+		}
+		return errors.FailedPrecondition
+	case mysqlLockWaitTimeout, mysqlLockDeadlock:
+		return errors.Aborted
+	case mysqlTooManyConnections:
+		return errors.ResourceExhausted
+	case mysqlBadNull, mysqlDataTooLong, mysqlTruncatedWrongValue, mysqlCheckConstraintViolate:
+		return errors.InvalidArgument
+	case mysqlWarnDataOutOfRange:
+		return errors.OutOfRange
+	default:
+		return errors.DatabaseError
+	}
+}
+
+// PostgreSQL error codes
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgNotNullViolation     = "23502"
+	pgCheckViolation       = "23514"
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgQueryCanceled        = "57014"
+	pgAdminShutdown        = "57P01"
+	pgNumericOutOfRange    = "22003"
+
+	pgClassDataException          = "22"
+	pgClassConnectionException    = "08"
+	pgClassInsufficientResources  = "53"
+	pgClassTransactionRollback    = "40"
+	pgClassIntegrityViolation     = "23"
+	pgClassInvalidTransactionTerm = "2D"
+)
+
+func classifyPostgres(err *pq.Error, fields errors.Fields) errors.Codes {
+	if err.Table != "" {
+		fields["table"] = err.Table
+	}
+	if err.Constraint != "" {
+		fields["constraint"] = err.Constraint
+	}
+	if err.Column != "" {
+		fields["column"] = err.Column
+	}
+
+	switch string(err.Code) {
+	case pgUniqueViolation:
+		return errors.AlreadyExists
+	case pgForeignKeyViolation:
+		return errors.FailedPrecondition
+	case pgNotNullViolation, pgCheckViolation:
+		return errors.InvalidArgument
+	case pgSerializationFailure, pgDeadlockDetected:
+		return errors.Aborted
+	case pgQueryCanceled:
+		return errors.Canceled
+	case pgAdminShutdown:
+		return errors.Unavailable
+	case pgNumericOutOfRange:
+		return errors.OutOfRange
+	}
+
+	switch string(err.Code.Class()) {
+	case pgClassDataException:
+		return errors.InvalidArgument
+	case pgClassConnectionException:
+		return errors.Unavailable
+	case pgClassInsufficientResources:
+		return errors.ResourceExhausted
+	case pgClassTransactionRollback:
+		return errors.Aborted
+	case pgClassIntegrityViolation, pgClassInvalidTransactionTerm:
+		return errors.FailedPrecondition
+	default:
+		return errors.DatabaseError
+	}
+}
@@ -0,0 +1,116 @@
+package sqlerr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/albert-widi/go_common/errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   errors.Codes
+		fields errors.Fields
+	}{
+		{
+			err:  sql.ErrNoRows,
+			code: errors.NotFound,
+		},
+		{
+			err:  fmt.Errorf("get order: %w", driver.ErrBadConn),
+			code: errors.Unavailable,
+		},
+		{
+			err:    &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '10' for key 'orders.idx_order_no'"},
+			code:   errors.AlreadyExists,
+			fields: errors.Fields{"db_code": "1062", "table": "orders", "constraint": "idx_order_no"},
+		},
+		{
+			err:    &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '10' for key 'idx_order_no'"},
+			code:   errors.AlreadyExists,
+			fields: errors.Fields{"db_code": "1062", "constraint": "idx_order_no"},
+		},
+		{
+			err:    &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`shop`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			code:   errors.FailedPrecondition,
+			fields: errors.Fields{"db_code": "1452", "table": "orders", "constraint": "fk_orders_user"},
+		},
+		{
+			err:    &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+			code:   errors.Aborted,
+			fields: errors.Fields{"db_code": "1213"},
+		},
+		{
+			err:    &mysql.MySQLError{Number: 1146, Message: "Table 'shop.orders' doesn't exist"},
+			code:   errors.DatabaseError,
+			fields: errors.Fields{"db_code": "1146"},
+		},
+		{
+			err:    &pq.Error{Code: "23505", Table: "orders", Constraint: "orders_order_no_key"},
+			code:   errors.AlreadyExists,
+			fields: errors.Fields{"db_code": "23505", "table": "orders", "constraint": "orders_order_no_key"},
+		},
+		{
+			err:    &pq.Error{Code: "23503", Table: "orders", Constraint: "orders_user_id_fkey"},
+			code:   errors.FailedPrecondition,
+			fields: errors.Fields{"db_code": "23503", "table": "orders", "constraint": "orders_user_id_fkey"},
+		},
+		{
+			err:    &pq.Error{Code: "40001"},
+			code:   errors.Aborted,
+			fields: errors.Fields{"db_code": "40001"},
+		},
+		{
+			err:    &pq.Error{Code: "08006"},
+			code:   errors.Unavailable,
+			fields: errors.Fields{"db_code": "08006"},
+		},
+		{
+			err:    &pq.Error{Code: "42P01"},
+			code:   errors.DatabaseError,
+			fields: errors.Fields{"db_code": "42P01"},
+		},
+	}
+
+	for _, val := range cases {
+		err := Classify(val.err).(*errors.Errs)
+		if err.GetCode() != val.code {
+			t.Errorf("%v: Expect %v but got %v", val.err, val.code, err.GetCode())
+		}
+		if !reflect.DeepEqual(err.GetFields(), val.fields) {
+			t.Errorf("%v: Expect %v but got %v", val.err, val.fields, err.GetFields())
+		}
+		if !errors.Is(err, val.err) {
+			t.Errorf("Expect %v to be found in chain", val.err)
+		}
+	}
+}
+
+func TestClassifyCaller(t *testing.T) {
+	errors.SetRuntimeOutput(true)
+	defer errors.SetRuntimeOutput(false)
+
+	err := Classify(sql.ErrNoRows).(*errors.Errs)
+	if file, _ := err.GetFileAndLine(); !strings.HasSuffix(file, "sqlerr_test.go") {
+		t.Errorf("Expect caller of Classify to be recorded but got %s", file)
+	}
+}
+
+func TestClassifyRetryable(t *testing.T) {
+	if !errors.IsRetryable(Classify(&pq.Error{Code: "40P01"})) {
+		t.Error("Expect deadlock to be retryable")
+	}
+	if errors.IsRetryable(Classify(&pq.Error{Code: "23505"})) {
+		t.Error("Expect unique violation to not be retryable")
+	}
+	if Classify(nil) != nil {
+		t.Error("Expect nil error to stay nil")
+	}
+}
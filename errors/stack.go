@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// stackDepth is read on every New and can be changed at runtime, so it is accessed atomically
+var stackDepth int32
+
+// SetStackOutput will record the full stack up to depth frames when error is created
+// depth 0 will disable the stack output
+func SetStackOutput(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	atomic.StoreInt32(&stackDepth, int32(depth))
+}
+
+// IsStackEnabled to check the status of stack output
+func IsStackEnabled() bool {
+	return getStackDepth() > 0
+}
+
+func getStackDepth() int {
+	return int(atomic.LoadInt32(&stackDepth))
+}
+
+// Frame is a resolved stack frame of Errs
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// callers record the program counters of the stack
+// skip 0 is the function calling callers
+func callers(skip, depth int) []uintptr {
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// GetStack return the stack frames of the error
+// the innermost stack in the chain is used as it is the closest to where the error is happened
+func (e *Errs) GetStack() []Frame {
+	var pcs []uintptr
+	for err := error(e); err != nil; err = Unwrap(err) {
+		if errs, ok := err.(*Errs); ok && len(errs.stack) > 0 {
+			pcs = errs.stack
+		}
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	frames := make([]Frame, 0, len(pcs))
+	callersFrames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := callersFrames.Next()
+		frames = append(frames, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// Format implement fmt.Formatter
+// %v and %s print the error message, %+v print messages, fields, code, ops, traces and stack of the error
+func (e *Errs) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.formatVerbose(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func (e *Errs) formatVerbose(w io.Writer) {
+	io.WriteString(w, e.Error())
+	if code := e.GetCode(); code != nil {
+		errString, httpCode := code.ErrorAndCode()
+		fmt.Fprintf(w, "\ncode: %s (%d)", errString, httpCode)
+	}
+	if ops := e.GetOps(); len(ops) > 0 {
+		opStrings := make([]string, len(ops))
+		for i := range ops {
+			opStrings[i] = string(ops[i])
+		}
+		fmt.Fprintf(w, "\nops: %s", strings.Join(opStrings, ", "))
+	}
+	if len(e.messages) > 0 {
+		fmt.Fprintf(w, "\nmessages: %s", strings.Join(e.messages, ", "))
+	}
+	if fields := RedactFields(e.fields); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		io.WriteString(w, "\nfields:")
+		for _, key := range keys {
+			fmt.Fprintf(w, " %s=%v", key, fields[key])
+		}
+	}
+	if traces := e.GetTrace(); len(traces) > 0 {
+		fmt.Fprintf(w, "\ntraces: %s", strings.Join(traces, ", "))
+	}
+	if frames := e.GetStack(); len(frames) > 0 {
+		io.WriteString(w, "\nstack:")
+		for _, frame := range frames {
+			fmt.Fprintf(w, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+	} else if file, line := e.GetFileAndLine(); line != 0 {
+		fmt.Fprintf(w, "\nfile: %s:%d", file, line)
+	}
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	SetStackOutput(5)
+	defer SetStackOutput(0)
+
+	err := New(Op("order.Get"), DatabaseError, "no rows", Fields{"order_id": 10})
+	frames := err.GetStack()
+	if len(frames) == 0 || len(frames) > 5 {
+		t.Fatalf("Expect 1 to 5 frames but got %d", len(frames))
+	}
+	if !strings.HasSuffix(frames[0].Function, "TestStack") {
+		t.Errorf("Expect first frame to be TestStack but got %s", frames[0].Function)
+	}
+	if file, line := err.GetFileAndLine(); !strings.HasSuffix(file, "stack_test.go") || line == 0 {
+		t.Errorf("Expect file and line from stack but got %s:%d", file, line)
+	}
+
+	wrapped := New(Op("order.Create"), err)
+	if wrapped.GetStack()[0] != frames[0] {
+		t.Errorf("Expect innermost stack to be used but got %v", wrapped.GetStack()[0])
+	}
+}
+
+func TestStackPolicy(t *testing.T) {
+	SetStackOutput(5)
+	defer SetStackOutput(0)
+	SetCapturePolicy(CapturePolicy{ClientError: CaptureNever})
+	defer SetCapturePolicy(CapturePolicy{})
+
+	cases := []struct {
+		err    *Errs
+		expect bool
+	}{
+		{New(DatabaseError), true},
+		{New(NotFound), false},
+		{NewWith(WithCode(DatabaseError), WithCapture(CaptureNever)), false},
+		{NewWith(WithCode(NotFound), WithCapture(CaptureAlways)), true},
+	}
+	for _, c := range cases {
+		if hasStack := len(c.err.GetStack()) > 0; hasStack != c.expect {
+			t.Errorf("Expect stack of %v to be recorded %v but got %v", c.err, c.expect, hasStack)
+		}
+		if _, line := c.err.GetFileAndLine(); (line != 0) != c.expect {
+			t.Errorf("Expect file and line of %v to be recorded %v but got line %d", c.err, c.expect, line)
+		}
+	}
+}
+
+func TestStackDisabled(t *testing.T) {
+	err := New("Some error")
+	if err.GetStack() != nil {
+		t.Errorf("Expect no stack but got %v", err.GetStack())
+	}
+	if file, line := err.GetFileAndLine(); file != "" || line != 0 {
+		t.Errorf("Expect no file and line but got %s:%d", file, line)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	SetStackOutput(5)
+	defer SetStackOutput(0)
+
+	err := New(Op("order.Get"), DatabaseError, "no rows", Fields{"order_id": 10}, []string{"stack1"})
+	if s := fmt.Sprintf("%v", err); s != err.Error() {
+		t.Errorf("Expect %s but got %s", err.Error(), s)
+	}
+	if s := fmt.Sprintf("%s", err); s != err.Error() {
+		t.Errorf("Expect %s but got %s", err.Error(), s)
+	}
+
+	verbose := fmt.Sprintf("%+v", err)
+	expects := []string{
+		"order.Get: Database error: no rows",
+		"code: Database error (500)",
+		"ops: order.Get",
+		"messages: stack1",
+		"fields: order_id=10",
+		"stack:",
+		"TestFormat",
+	}
+	for _, expect := range expects {
+		if !strings.Contains(verbose, expect) {
+			t.Errorf("Expect %q in %s", expect, verbose)
+		}
+	}
+}
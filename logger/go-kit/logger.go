@@ -230,20 +230,28 @@ func formatFilePath(f string) string {
 
 // Errors should be called by using errors package
 // errors package have special error fields to add more context in error
+// the log level is chosen from the severity of the error, so expected errors like NotFound is logged as info
 func (l *Logger) Errors(err error) {
 	var (
-		errFields errors.Fields
-		file      string
-		line      int
+		errFields   errors.Fields
+		file        string
+		line        int
+		traces      []string
+		fingerprint string
 	)
 	switch err.(type) {
 	case *errors.Errs:
 		errs := err.(*errors.Errs)
-		errFields = errs.GetFields()
+		errFields = errs.GetRedactedFields()
 		file, line = errs.GetFileAndLine()
+		traces = errs.GetTrace()
+		fingerprint = errs.Fingerprint()
 	}
 	// transform error fields to log fields
 	logFields := Fields(errFields)
+	if logFields == nil {
+		logFields = make(Fields)
+	}
 	// copy from fields if exists
 	for key, value := range l.fields {
 		if _, ok := logFields[key]; !ok {
@@ -255,8 +263,31 @@ func (l *Logger) Errors(err error) {
 		logFields["err_file"] = formatFilePath(file)
 		logFields["err_line"] = line
 	}
-	l.fields = logFields
-	l.print(ErrorLevel, err.Error())
+	if len(traces) > 0 {
+		logFields["err_traces"] = traces
+	}
+	if fingerprint != "" {
+		logFields["err_fingerprint"] = fingerprint
+	}
+	// the fields is set on a copy of logger, so a shared logger can log errors concurrently
+	logger := *l
+	logger.fields = logFields
+	errors.RunHooks(errors.HookLog, err)
+	logger.print(severityToLevel(errors.GetSeverity(err)), err.Error())
+}
+
+// severityToLevel choose the log level from severity of the error
+func severityToLevel(s errors.Severity) Level {
+	switch s {
+	case errors.SeverityDebug:
+		return DebugLevel
+	case errors.SeverityInfo:
+		return InfoLevel
+	case errors.SeverityWarning:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
 }
 
 func (l *Logger) Fatal(msg ...interface{}) {
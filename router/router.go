@@ -2,13 +2,13 @@ package router
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	errs "github.com/albert-widi/go_common/errors"
 	"github.com/eapache/go-resiliency/breaker"
 	"github.com/pressly/chi"
 	"github.com/prometheus/client_golang/prometheus"
@@ -80,8 +80,7 @@ func (rtr *Router) circuitbreaker(h http.HandlerFunc) http.HandlerFunc {
 		if err == nil || err != breaker.ErrBreakerOpen {
 			return
 		}
-		w.WriteHeader(http.StatusServiceUnavailable)
-		w.Write([]byte("Service unavailable"))
+		WriteError(w, r, errs.New(errs.ServiceNotAvailableError))
 	}
 }
 
@@ -105,14 +104,7 @@ func (rtr *Router) timeout(h http.HandlerFunc) http.HandlerFunc {
 		}()
 		select {
 		case <-r.Context().Done():
-			// only an example response
-			resp := map[string]interface{}{
-				"errors": []string{"Request timed out"},
-			}
-			jsonResp, _ := json.Marshal(resp)
-			w.WriteHeader(http.StatusRequestTimeout)
-			// only an example response
-			w.Write(jsonResp)
+			WriteError(w, r, errs.New(errs.RequestTimeOutError))
 			return
 		case <-doneChan:
 			return
@@ -120,6 +112,12 @@ func (rtr *Router) timeout(h http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// WriteError write the error to http response as JSON
+// the user message is localized by the Accept-Language header of the request
+func WriteError(w http.ResponseWriter, r *http.Request, err error, fields ...string) error {
+	return errs.WriteHTTPLang(w, err, r.Header.Get("Accept-Language"), fields...)
+}
+
 // responseWriterDelegator to delegate the current writer
 // this is a 100% from prometheus delegator with some modification
 // the modification is needed because namespace is required